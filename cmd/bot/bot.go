@@ -9,18 +9,52 @@ import (
 )
 
 func NewBot(ctx context.Context, logger *slog.Logger) *telegram.Bot {
-	bot, err := telegram.NewBot(
-		ctx,
+	opts := []func(*telegram.Bot){
 		telegram.WithToken(os.Getenv("TELEGRAM_BOT_TOKEN")),
 		telegram.WithLogger(logger),
 		telegram.WithClient(NewHttpClient(logger)),
-	)
+	}
+
+	if endpoint := os.Getenv("TELEGRAM_API_ENDPOINT"); endpoint != "" {
+		logger.Info("using custom bot api server", "endpoint", endpoint)
+		if os.Getenv("TELEGRAM_LOGOUT_FROM_CLOUD") == "true" {
+			logOutFromCloud(ctx, logger)
+		}
+		opts = append(opts,
+			telegram.WithEndpoint(endpoint),
+			telegram.WithLocalMode(os.Getenv("TELEGRAM_LOCAL_MODE") == "true"),
+		)
+	}
+
+	bot, err := telegram.NewBot(ctx, opts...)
 
 	if err != nil {
 		panic(err)
 	}
 
-	logger.Info("bot created", "username", bot.Username)
+	logger.Info("bot created", "username", bot.Username, "upload_limit", bot.UploadLimit())
 
 	return bot
 }
+
+// logOutFromCloud is required once before switching to a self-hosted Bot API server
+// https://core.telegram.org/bots/api#logout
+func logOutFromCloud(ctx context.Context, logger *slog.Logger) {
+	bot, err := telegram.NewBot(
+		ctx,
+		telegram.WithToken(os.Getenv("TELEGRAM_BOT_TOKEN")),
+		telegram.WithLogger(logger),
+		telegram.WithClient(NewHttpClient(logger)),
+	)
+	if err != nil {
+		logger.Warn("failed to reach cloud bot api, probably logged out already", "error", err)
+		return
+	}
+
+	if err := bot.LogOut(ctx); err != nil {
+		logger.Error("failed to log out from cloud bot api", "error", err)
+		return
+	}
+
+	logger.Info("logged out from cloud bot api, unset TELEGRAM_LOGOUT_FROM_CLOUD now")
+}
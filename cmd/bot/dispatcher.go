@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/internal/router"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+// maxPending bounds the updates accepted but not finished yet, it is way above
+// the getUpdates limit, so a single busy chat doesn't hold the others back
+const maxPending = 1000
+
+// NewDispatcher creates a dispatcher, `ordered` is for long polling where update
+// identifiers only grow, webhooks could be delivered concurrently and out of order
+func NewDispatcher(logger *slog.Logger, handler UpdateHandler, ledger *Ledger, workers int, ordered bool) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		l:        logger,
+		ordered:  ordered,
+		ctx:      ctx,
+		cancel:   cancel,
+		handler:  handler,
+		ledger:   ledger,
+		workers:  make(chan struct{}, max(workers, 1)),
+		queues:   map[int64][]*telegram.Update{},
+		pending:  map[int64]struct{}{},
+		finished: make(chan struct{}, 1),
+	}
+}
+
+// Dispatcher processes updates of different chats in parallel
+// while keeping the order of updates within a chat
+type Dispatcher struct {
+	l       *slog.Logger
+	ordered bool
+	// ctx outlives the polling one, so the running handlers could finish on shutdown
+	ctx      context.Context
+	cancel   context.CancelFunc
+	handler  UpdateHandler
+	ledger   *Ledger
+	workers  chan struct{}
+	mu       sync.Mutex
+	queues   map[int64][]*telegram.Update
+	pending  map[int64]struct{}
+	last     int64
+	stopped  bool
+	wg       sync.WaitGroup
+	finished chan struct{}
+}
+
+// Dispatch schedules the update and reports false for one seen already
+func (d *Dispatcher) Dispatch(u *telegram.Update, bot *telegram.Bot) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.pending[u.ID]; ok || d.stopped {
+		return false
+	}
+	// the updates processed already are skipped by the ledger otherwise
+	if d.ordered && u.ID <= d.last {
+		return false
+	}
+
+	d.last = max(d.last, u.ID)
+	d.pending[u.ID] = struct{}{}
+
+	// updates of a chat have to be processed in order
+	key := router.ChatID(u)
+	queue, running := d.queues[key]
+	d.queues[key] = append(queue, u)
+	if !running {
+		d.wg.Add(1)
+		go d.drain(key, bot)
+	}
+	return true
+}
+
+// Offset follows the last dispatched update, the updates before it are confirmed
+// while still running, so they don't come back with every getUpdates
+func (d *Dispatcher) Offset() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.last == 0 {
+		return 0
+	}
+	return d.last + 1
+}
+
+// Free is the number of updates the dispatcher could take right now
+func (d *Dispatcher) Free() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return max(maxPending-len(d.pending), 0)
+}
+
+// Finished fires when any of the updates is processed
+func (d *Dispatcher) Finished() <-chan struct{} {
+	return d.finished
+}
+
+// Shutdown stops taking updates and waits for the queued ones within the grace period.
+// The handlers still running after it are cancelled, the rest of the queue is dropped.
+func (d *Dispatcher) Shutdown(grace time.Duration) {
+	d.mu.Lock()
+	d.stopped = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return
+	case <-time.After(grace):
+	}
+
+	d.l.Warn("grace period is over, cancelling running handlers", "grace_period", grace)
+	d.cancel()
+	<-done
+}
+
+func (d *Dispatcher) drain(key int64, bot *telegram.Bot) {
+	defer d.wg.Done()
+	for {
+		d.mu.Lock()
+		queue := d.queues[key]
+		if len(queue) == 0 {
+			delete(d.queues, key)
+			d.mu.Unlock()
+			return
+		}
+		u := queue[0]
+		d.queues[key] = queue[1:]
+		d.mu.Unlock()
+
+		d.process(u, bot)
+	}
+}
+
+func (d *Dispatcher) process(u *telegram.Update, bot *telegram.Bot) {
+	ctx := d.ctx
+	select {
+	case d.workers <- struct{}{}:
+	case <-ctx.Done():
+		d.l.Warn("update dropped on shutdown", "update_id", u.ID)
+		return
+	}
+	defer func() { <-d.workers }()
+
+	if d.ledger.Processed(ctx, u.ID) {
+		d.l.Info("skipping update processed already", "update_id", u.ID)
+	} else {
+		processUpdate(ctx, d.l, bot, d.handler, u)
+		if ctx.Err() != nil {
+			return // interrupted by shutdown, it's not recorded, so it's handled again if redelivered
+		}
+		d.ledger.Done(ctx, u.ID)
+	}
+
+	d.mu.Lock()
+	delete(d.pending, u.ID)
+	d.mu.Unlock()
+
+	select {
+	case d.finished <- struct{}{}:
+	default:
+	}
+}
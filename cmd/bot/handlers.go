@@ -2,13 +2,20 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"log/slog"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/ailinykh/reposter/v3/internal/fotd"
 	"github.com/ailinykh/reposter/v3/internal/hotlink"
 	"github.com/ailinykh/reposter/v3/internal/info"
+	"github.com/ailinykh/reposter/v3/internal/lifecycle"
+	"github.com/ailinykh/reposter/v3/internal/report"
 	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/internal/router"
 	"github.com/ailinykh/reposter/v3/internal/xui"
 	"github.com/ailinykh/reposter/v3/pkg/telegram"
 	"github.com/ailinykh/reposter/v3/pkg/xcom"
@@ -19,23 +26,23 @@ type UpdateHandler interface {
 	Handle(context.Context, *telegram.Update, *telegram.Bot) error
 }
 
-func makeHandlers(
+func makeRouter(
 	logger *slog.Logger,
+	db *sql.DB,
 	repo *repository.Queries,
-) []UpdateHandler {
-	handlers := []UpdateHandler{
-		fotd.NewGame(logger.With("handler", "fotd"), repo),
-		info.New(),
-		hotlink.New(
-			logger.With("handler", "hotlink"),
-			repo,
-			xcom.New(logger),
-			ytdlp.New(
-				ytdlp.WithArgs(getYtDlpArgs()),
-				ytdlp.WithLogger(logger.With("tool", "yt-dlp")),
-			),
-		),
-	}
+	reporter *report.Reporter,
+	tempDir string,
+) *router.Router {
+	r := router.New(router.WithTimeout(getHandlerTimeout()))
+	r.Use(
+		router.Logging(logger),
+		reporter.Middleware(),
+		router.Recovery(logger),
+	)
+
+	lifecycle.New(logger.With("handler", "lifecycle"), db).Register(r)
+	fotd.NewGame(logger.With("handler", "fotd"), repo).Register(r)
+	info.New().Register(r)
 
 	baseUrl := os.Getenv("XUI_BASE_URL")
 	login := os.Getenv("XUI_LOGIN")
@@ -43,12 +50,55 @@ func makeHandlers(
 	if baseUrl != "" && login != "" && password != "" {
 		logger.Info("xui vpn logic enabled", "username", login)
 		client := xui.NewClient(logger.With("handler", "xui"), baseUrl, login, password)
-		handlers = append(handlers, xui.NewHandler(client, logger.With("handler", "xui"), repo))
+		xui.NewHandler(client, logger.With("handler", "xui"), repo).Register(r)
 	} else {
 		logger.Info("xui vpn logic disabled")
 	}
 
-	return handlers
+	// should go last to skip messages handled by the others
+	hotlinkLogger := logger.With("handler", "hotlink")
+	hotlink.New(
+		hotlinkLogger,
+		repo,
+		hotlink.NewRegistry(hotlinkLogger, getExtractors(hotlinkLogger, tempDir)...),
+	).Register(r)
+
+	return r
+}
+
+// getExtractors returns the hotlink extractors enabled in the config, in the order they are tried
+func getExtractors(logger *slog.Logger, tempDir string) []hotlink.Extractor {
+	available := map[string]hotlink.Extractor{
+		"xcom": hotlink.NewXcomExtractor(logger, xcom.New(logger)),
+		"ytdlp": hotlink.NewYtDlpExtractor(logger, ytdlp.New(
+			ytdlp.WithArgs(getYtDlpArgs()),
+			ytdlp.WithTempDir(tempDir),
+			ytdlp.WithLogger(logger.With("tool", "yt-dlp")),
+		), tempDir),
+		"direct": hotlink.NewDirectExtractor(logger, http.DefaultClient),
+	}
+
+	names := "xcom,ytdlp,direct"
+	if value, ok := os.LookupEnv("HOTLINK_EXTRACTORS"); ok {
+		names = value
+	}
+
+	var extractors []hotlink.Extractor
+	for name := range strings.SplitSeq(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		e, ok := available[name]
+		if !ok {
+			logger.Warn("unknown hotlink extractor", "name", name)
+			continue
+		}
+		extractors = append(extractors, e)
+	}
+
+	logger.Info("hotlink extractors enabled", "names", names)
+	return extractors
 }
 
 func getYtDlpArgs() []string {
@@ -58,3 +108,10 @@ func getYtDlpArgs() []string {
 	}
 	return args
 }
+
+func getHandlerTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(os.Getenv("HANDLER_TIMEOUT")); err == nil {
+		return timeout
+	}
+	return time.Minute
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ServeHTTP reports the polling health, the status is 503 when polling doesn't work,
+// so it could be used as a readiness probe
+func (p *Poller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h := p.Health()
+
+	status := http.StatusOK
+	if h.State == HealthDegraded || h.State == HealthFailed {
+		status = http.StatusServiceUnavailable
+	}
+
+	body := map[string]any{
+		"state":    h.State,
+		"failures": h.Failures,
+	}
+	if h.LastError != nil {
+		body["last_error"] = h.LastError.Error()
+	}
+	if !h.LastPoll.IsZero() {
+		body["last_poll"] = h.LastPoll
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// startHealthServer serves the health at `/healthz` until the context is done
+func startHealthServer(ctx context.Context, logger *slog.Logger, addr string, health http.Handler) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /healthz", health)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	go func() {
+		logger.Info("health server started", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("health server failed", "error", err)
+		}
+	}()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/internal/repository"
+)
+
+// Telegram keeps unconfirmed updates for 24 hours, anything older can't be redelivered
+const ledgerRetention = 48 * time.Hour
+
+type LedgerRepo interface {
+	GetUpdateOffset(ctx context.Context, botID int64) (repository.UpdateOffset, error)
+	SetUpdateOffset(ctx context.Context, arg repository.SetUpdateOffsetParams) error
+	IsUpdateProcessed(ctx context.Context, arg repository.IsUpdateProcessedParams) (bool, error)
+	CreateProcessedUpdate(ctx context.Context, arg repository.CreateProcessedUpdateParams) error
+	DeleteProcessedUpdates(ctx context.Context, arg repository.DeleteProcessedUpdatesParams) error
+}
+
+func NewLedger(logger *slog.Logger, repo LedgerRepo, botID int64) *Ledger {
+	return &Ledger{
+		l:     logger,
+		repo:  repo,
+		botID: botID,
+	}
+}
+
+// Ledger persists the getUpdates offset and the updates processed already,
+// so the updates redelivered after a restart are not handled twice
+type Ledger struct {
+	l     *slog.Logger
+	repo  LedgerRepo
+	botID int64
+}
+
+// Offset returns the offset confirmed last time, or zero for the first run
+func (l *Ledger) Offset(ctx context.Context) int64 {
+	offset, err := l.repo.GetUpdateOffset(ctx, l.botID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			l.l.Error("failed to get update offset", "error", err)
+		}
+		return 0
+	}
+	return offset.NextUpdateID
+}
+
+// SetOffset stores the offset, it never goes backwards
+func (l *Ledger) SetOffset(ctx context.Context, offset int64) {
+	if err := l.repo.SetUpdateOffset(ctx, repository.SetUpdateOffsetParams{
+		BotID:        l.botID,
+		NextUpdateID: offset,
+	}); err != nil {
+		l.l.Error("failed to set update offset", "offset", offset, "error", err)
+	}
+}
+
+// Processed reports whether the update has been handled already.
+// The update is handled again when the ledger is not available.
+func (l *Ledger) Processed(ctx context.Context, updateID int64) bool {
+	processed, err := l.repo.IsUpdateProcessed(ctx, repository.IsUpdateProcessedParams{
+		BotID:    l.botID,
+		UpdateID: updateID,
+	})
+	if err != nil {
+		l.l.Error("failed to check processed update", "update_id", updateID, "error", err)
+		return false
+	}
+	return processed
+}
+
+// Done records the update once the handler has finished with it, so an update
+// interrupted by a crash is handled again after restart (at-least-once delivery)
+func (l *Ledger) Done(ctx context.Context, updateID int64) {
+	if err := l.repo.CreateProcessedUpdate(ctx, repository.CreateProcessedUpdateParams{
+		BotID:    l.botID,
+		UpdateID: updateID,
+	}); err != nil {
+		l.l.Error("failed to record processed update", "update_id", updateID, "error", err)
+	}
+}
+
+// Prune forgets the updates which can't be redelivered anymore
+func (l *Ledger) Prune(ctx context.Context) {
+	if err := l.repo.DeleteProcessedUpdates(ctx, repository.DeleteProcessedUpdatesParams{
+		BotID:     l.botID,
+		CreatedAt: time.Now().Add(-ledgerRetention),
+	}); err != nil {
+		l.l.Error("failed to prune processed updates", "error", err)
+	}
+}
+
+// PruneEvery prunes the ledger right away and then periodically until the context is done
+func (l *Ledger) PruneEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		l.Prune(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
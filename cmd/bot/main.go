@@ -5,51 +5,105 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/ailinykh/reposter/v3/internal/log"
+	"github.com/ailinykh/reposter/v3/internal/report"
 	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/internal/router"
 	"github.com/ailinykh/reposter/v3/pkg/telegram"
 )
 
 func main() {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	logger := log.NewLogger()
+	sweepTempDirs(logger)
+	tempDir := makeTempDir(logger)
 	bot := NewBot(ctx, logger)
-	repo := repository.New(NewDB(logger))
+	db := NewDB(logger)
+	repo := repository.New(db)
+	reporter := report.New(logger.With("component", "report"), getAdminChatID())
+	router := makeRouter(logger, db, repo, reporter, tempDir)
+	if err := router.PublishCommands(ctx, bot); err != nil {
+		logger.Error("failed to publish commands", "error", err)
+	}
+	ledger := NewLedger(logger, repo, bot.ID)
+	go ledger.PruneEvery(ctx, time.Hour)
+	webhookURL := os.Getenv("TELEGRAM_WEBHOOK_URL")
+	dispatcher := NewDispatcher(logger, reporter.Recover(router), ledger, getWorkers(), webhookURL == "")
 
-	startRunLoop(ctx, logger, bot, makeHandlers(logger, repo))
-	logger.Info("attempt to shutdown gracefully...")
+	failed := false
+	if webhookURL != "" {
+		if err := startWebhook(ctx, logger, bot, dispatcher, webhookURL, router.AllowedUpdates()); err != nil {
+			logger.Error("webhook stopped", "error", err)
+			failed = true
+		}
+	} else {
+		poller := NewPoller(logger, bot, dispatcher, ledger, router.AllowedUpdates())
+		if addr := os.Getenv("HEALTH_LISTEN"); addr != "" {
+			startHealthServer(ctx, logger, addr, poller)
+		}
+		if err := poller.Run(ctx); err != nil {
+			logger.Error("polling stopped", "error", err)
+			failed = true
+		}
+	}
+	shutdown(logger, dispatcher, tempDir)
+	if webhookURL == "" {
+		confirmOffset(logger, bot, dispatcher, ledger)
+	}
+	if failed {
+		cancel()
+		os.Exit(1)
+	}
 }
 
-func startRunLoop(
+// confirmOffset stores the offset following the last dispatched update
+// and lets Telegram know all the updates before it are taken
+func confirmOffset(logger *slog.Logger, bot *telegram.Bot, dispatcher *Dispatcher, ledger *Ledger) {
+	offset := dispatcher.Offset()
+	if offset == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ledger.SetOffset(ctx, offset)
+	if _, err := bot.GetUpdates(ctx, &telegram.GetUpdatesParams{
+		Offset: offset,
+		Limit:  1,
+	}); err != nil {
+		logger.Error("failed to confirm updates", "offset", offset, "error", err)
+		return
+	}
+	logger.Info("updates confirmed", "offset", offset)
+}
+
+func processUpdate(
 	ctx context.Context,
 	logger *slog.Logger,
 	bot *telegram.Bot,
-	handlers []UpdateHandler,
+	handler UpdateHandler,
+	update *telegram.Update,
 ) {
-	var offset int64 = 0
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			updates, err := bot.GetUpdates(ctx, &telegram.GetUpdatesParams{Offset: offset, Timeout: 300})
-			if err != nil {
-				logger.Error("failed to get updates", "error", err)
-				break
-			}
-
-			for _, update := range updates {
-				logger.Info("processing update", "update_id", update.ID, "message", update.Message)
-				for _, handler := range handlers {
-					if err := handler.Handle(ctx, update, bot); err != nil {
-						logger.Error("❌ failed to process update", "handler", handler, "error", err)
-					}
-				}
-				offset = update.ID + 1
-			}
-		}
+	logger.Info("processing update", "update_id", update.ID, "kind", router.KindOf(update), "message", update.Message)
+	// errors are logged by the router middleware
+	_ = handler.Handle(ctx, update, bot)
+}
+
+func getAdminChatID() int64 {
+	chatID, _ := strconv.ParseInt(os.Getenv("ADMIN_CHAT_ID"), 10, 64)
+	return chatID
+}
+
+func getWorkers() int {
+	if workers, err := strconv.Atoi(os.Getenv("WORKERS")); err == nil {
+		return workers
 	}
+	return 8
 }
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+const (
+	minPollBackoff = time.Second
+	maxPollBackoff = time.Minute
+	// another instance could still be running during a deploy, give it some time to stop
+	maxPollConflicts = 5
+	// maxPollLimit is the most updates getUpdates returns at once
+	maxPollLimit = 100
+)
+
+type HealthState string
+
+const (
+	HealthStarting HealthState = "starting"
+	HealthOK       HealthState = "ok"
+	// HealthDegraded means getUpdates fails and the poller is backing off
+	HealthDegraded HealthState = "degraded"
+	// HealthFailed means the poller has given up
+	HealthFailed HealthState = "failed"
+)
+
+// Health is a snapshot of the polling state
+type Health struct {
+	State HealthState
+	// Failures is the number of getUpdates failures in a row
+	Failures  int
+	LastError error
+	LastPoll  time.Time
+}
+
+func NewPoller(
+	logger *slog.Logger,
+	bot *telegram.Bot,
+	dispatcher *Dispatcher,
+	ledger *Ledger,
+	allowedUpdates []string,
+) *Poller {
+	return &Poller{
+		l:              logger,
+		bot:            bot,
+		dispatcher:     dispatcher,
+		ledger:         ledger,
+		allowedUpdates: allowedUpdates,
+		health:         Health{State: HealthStarting},
+	}
+}
+
+// Poller supervises the getUpdates loop: it backs off on failures
+// and gives up when the bot is consumed by someone else
+type Poller struct {
+	l              *slog.Logger
+	bot            *telegram.Bot
+	dispatcher     *Dispatcher
+	ledger         *Ledger
+	allowedUpdates []string
+	mu             sync.Mutex
+	health         Health
+}
+
+// Health returns the current polling state, it is safe for concurrent use
+func (p *Poller) Health() Health {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.health
+}
+
+// Run polls for updates until the context is done,
+// an error is returned when polling can't be continued
+func (p *Poller) Run(ctx context.Context) error {
+	// getUpdates does not work while an outgoing webhook is set up
+	if err := p.bot.DeleteWebhook(ctx, &telegram.DeleteWebhookParams{}); err != nil {
+		p.l.Error("failed to delete webhook", "error", err)
+	}
+
+	offset := p.ledger.Offset(ctx)
+	p.l.Info("starting from offset", "offset", offset)
+
+	conflicts := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		// the dispatcher takes a limited number of updates at once
+		free := p.dispatcher.Free()
+		if free == 0 {
+			select {
+			case <-ctx.Done():
+			case <-p.dispatcher.Finished():
+			}
+			continue
+		}
+
+		if next := p.dispatcher.Offset(); next > offset {
+			offset = next
+			p.ledger.SetOffset(ctx, offset)
+		}
+
+		updates, err := p.bot.GetUpdates(ctx, &telegram.GetUpdatesParams{
+			Offset:         offset,
+			Limit:          min(free, maxPollLimit),
+			Timeout:        300,
+			AllowedUpdates: p.allowedUpdates,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			var e *telegram.Error
+			if errors.As(err, &e) && e.WebhookActive() {
+				return p.fail(fmt.Errorf("a webhook has been set up for the bot, polling is not possible until it is deleted: %w", err))
+			}
+			if errors.As(err, &e) && e.Conflict() {
+				if conflicts++; conflicts >= maxPollConflicts {
+					return p.fail(fmt.Errorf("another instance is polling with the same bot token: %w", err))
+				}
+			}
+
+			delay := p.failure(err)
+			p.l.Error("failed to get updates", "error", err, "failures", p.Health().Failures, "retry_in", delay)
+			sleep(ctx, delay)
+			continue
+		}
+
+		conflicts = 0
+		p.success()
+
+		for _, update := range updates {
+			p.dispatcher.Dispatch(update, p.bot)
+		}
+	}
+}
+
+func (p *Poller) success() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.health.State == HealthDegraded {
+		p.l.Info("polling recovered", "failures", p.health.Failures)
+	}
+	p.health = Health{
+		State:    HealthOK,
+		LastPoll: time.Now(),
+	}
+}
+
+// failure records the error and returns the delay before the next attempt
+func (p *Poller) failure(err error) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.health.State = HealthDegraded
+	p.health.LastError = err
+	p.health.Failures++
+	return backoff(p.health.Failures)
+}
+
+func (p *Poller) fail(err error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.health.State = HealthFailed
+	p.health.LastError = err
+	return err
+}
+
+// backoff doubles the delay with every failure and adds jitter
+// so several instances don't retry at the same moment
+func backoff(failures int) time.Duration {
+	delay := maxPollBackoff
+	if shift := failures - 1; shift < 16 {
+		delay = min(minPollBackoff<<shift, maxPollBackoff)
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
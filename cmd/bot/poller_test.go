@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/telegramtest"
+)
+
+func TestPollerBusyChat(t *testing.T) {
+	server := telegramtest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	handler := &blockingHandler{
+		release: make(chan struct{}),
+		handled: make(chan int64, 1000),
+	}
+	ledger := NewLedger(logger, newLedgerRepo(), bot.ID)
+	dispatcher := NewDispatcher(logger, handler, ledger, 8, true)
+	poller := NewPoller(logger, bot, dispatcher, ledger, nil)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() { done <- poller.Run(ctx) }()
+	defer func() {
+		close(handler.release)
+		cancel()
+		<-done
+		dispatcher.Shutdown(time.Second)
+	}()
+
+	// the busy chat has way more unfinished updates than getUpdates returns at once
+	for range maxPollLimit + 50 {
+		server.AddUpdate(&telegram.Update{Message: &telegram.Message{Chat: &telegram.Chat{ID: 1}}})
+	}
+	other := server.AddUpdate(&telegram.Update{Message: &telegram.Message{Chat: &telegram.Chat{ID: 2}}})
+
+	select {
+	case id := <-handler.handled:
+		if id != other.ID {
+			t.Fatalf("handled update %d, want %d", id, other.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the update of another chat is stuck behind the busy one")
+	}
+
+	// the unfinished updates are not fetched again and again
+	before := len(server.Calls("getUpdates"))
+	time.Sleep(200 * time.Millisecond)
+	if calls := len(server.Calls("getUpdates")) - before; calls > 1 {
+		t.Errorf("getUpdates called %d times while waiting for updates", calls)
+	}
+}
+
+// blockingHandler holds the updates of chat 1 until released
+type blockingHandler struct {
+	release chan struct{}
+	handled chan int64
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+	if u.Message.Chat.ID == 1 {
+		select {
+		case <-h.release:
+		case <-ctx.Done():
+		}
+		return nil
+	}
+	h.handled <- u.ID
+	return nil
+}
+
+type ledgerRepo struct {
+	mu        sync.Mutex
+	offset    int64
+	processed map[int64]bool
+}
+
+func newLedgerRepo() *ledgerRepo {
+	return &ledgerRepo{processed: map[int64]bool{}}
+}
+
+func (r *ledgerRepo) GetUpdateOffset(ctx context.Context, botID int64) (repository.UpdateOffset, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.offset == 0 {
+		return repository.UpdateOffset{}, sql.ErrNoRows
+	}
+	return repository.UpdateOffset{BotID: botID, NextUpdateID: r.offset}, nil
+}
+
+func (r *ledgerRepo) SetUpdateOffset(ctx context.Context, arg repository.SetUpdateOffsetParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offset = max(r.offset, arg.NextUpdateID)
+	return nil
+}
+
+func (r *ledgerRepo) IsUpdateProcessed(ctx context.Context, arg repository.IsUpdateProcessedParams) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.processed[arg.UpdateID], nil
+}
+
+func (r *ledgerRepo) CreateProcessedUpdate(ctx context.Context, arg repository.CreateProcessedUpdateParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.processed[arg.UpdateID] = true
+	return nil
+}
+
+func (r *ledgerRepo) DeleteProcessedUpdates(ctx context.Context, arg repository.DeleteProcessedUpdatesParams) error {
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// shutdown lets the running handlers finish within the grace period,
+// the cancelled ones kill their child processes, and removes the media left behind
+func shutdown(logger *slog.Logger, dispatcher *Dispatcher, tempDir string) {
+	grace := getGracePeriod()
+	logger.Info("attempt to shutdown gracefully...", "grace_period", grace)
+	dispatcher.Shutdown(grace)
+	removeTempDir(logger, tempDir)
+}
+
+const (
+	tempDirPattern  = "reposter*"
+	tempDirLockFile = ".lock"
+)
+
+// makeTempDir creates the directory all the media of this process is processed in,
+// so removing it doesn't touch the files of other instances
+func makeTempDir(logger *slog.Logger) string {
+	dir, err := os.MkdirTemp("", tempDirPattern)
+	if err != nil {
+		logger.Error("failed to create temporary directory", "error", err)
+		panic(err)
+	}
+	if err = lockTempDir(dir); err != nil {
+		logger.Error("failed to lock temporary directory", "path", dir, "error", err)
+		panic(err)
+	}
+	logger.Info("temporary directory created", "path", dir)
+	return dir
+}
+
+// sweepTempDirs removes the directories of the instances which didn't shut down
+// gracefully, the ones still in use by running instances are skipped
+func sweepTempDirs(logger *slog.Logger) {
+	paths, err := filepath.Glob(filepath.Join(os.TempDir(), tempDirPattern))
+	if err != nil {
+		logger.Error("failed to find temporary directories", "error", err)
+		return
+	}
+
+	for _, path := range paths {
+		if staleTempDir(path) {
+			removeTempDir(logger, path)
+		}
+	}
+}
+
+func removeTempDir(logger *slog.Logger, dir string) {
+	if err := os.RemoveAll(dir); err != nil {
+		logger.Error("failed to remove temporary directory", "path", dir, "error", err)
+		return
+	}
+	logger.Info("temporary directory removed", "path", dir)
+}
+
+func getGracePeriod() time.Duration {
+	if grace, err := time.ParseDuration(os.Getenv("SHUTDOWN_GRACE_PERIOD")); err == nil {
+		return grace
+	}
+	return 30 * time.Second
+}
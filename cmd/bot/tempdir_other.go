@@ -0,0 +1,19 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// lockTempDir is a no-op, the directories are told apart by age
+func lockTempDir(dir string) error {
+	return nil
+}
+
+// staleTempDir reports whether the directory is older than any download could take
+func staleTempDir(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && time.Since(info.ModTime()) > 24*time.Hour
+}
@@ -0,0 +1,45 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// tempDirLock is kept open, so the lock lives as long as the process
+var tempDirLock *os.File
+
+// lockTempDir takes an exclusive lock on the directory, the kernel releases it
+// whenever the process dies, even after SIGKILL
+func lockTempDir(dir string) error {
+	f, err := os.OpenFile(filepath.Join(dir, tempDirLockFile), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return err
+	}
+	tempDirLock = f
+	return nil
+}
+
+// staleTempDir reports whether no running instance holds the directory
+func staleTempDir(dir string) bool {
+	f, err := os.Open(filepath.Join(dir, tempDirLockFile))
+	if err != nil {
+		// the instance could be just about to lock it
+		info, err := os.Stat(dir)
+		return err == nil && time.Since(info.ModTime()) > time.Minute
+	}
+	defer f.Close()
+
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return false
+	}
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return true
+}
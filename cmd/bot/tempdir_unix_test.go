@@ -0,0 +1,36 @@
+//go:build unix
+
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSweepTempDirs(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	logger := slog.New(slog.DiscardHandler)
+
+	live := makeTempDir(logger)
+	defer tempDirLock.Close()
+
+	// left behind by a killed instance, the lock file is there but nobody holds it
+	stale, err := os.MkdirTemp("", tempDirPattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(stale, tempDirLockFile), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sweepTempDirs(logger)
+
+	if _, err := os.Stat(live); err != nil {
+		t.Errorf("live directory removed: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale directory kept: %v", err)
+	}
+}
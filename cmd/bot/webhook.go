@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+func startWebhook(
+	ctx context.Context,
+	logger *slog.Logger,
+	bot *telegram.Bot,
+	dispatcher *Dispatcher,
+	webhookURL string,
+	allowedUpdates []string,
+) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse webhook url %s: %w", webhookURL, err)
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	addr := ":8080"
+	if value := os.Getenv("TELEGRAM_WEBHOOK_LISTEN"); value != "" {
+		addr = value
+	}
+
+	// every instance behind the webhook url has to accept the same token
+	secretToken := os.Getenv("TELEGRAM_WEBHOOK_SECRET")
+	if secretToken == "" {
+		return errors.New("TELEGRAM_WEBHOOK_SECRET is required in webhook mode")
+	}
+	updates := make(chan *telegram.Update)
+
+	mux := http.NewServeMux()
+	mux.Handle(path, bot.WebhookHandler(secretToken, updates))
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// the port is taken before Telegram is told to deliver there
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("webhook server started", "addr", addr, "path", path)
+		serverErr <- server.Serve(listener)
+	}()
+
+	if err := bot.SetWebhook(ctx, &telegram.SetWebhookParams{
+		URL:            webhookURL,
+		SecretToken:    secretToken,
+		AllowedUpdates: allowedUpdates,
+	}); err != nil {
+		_ = server.Close()
+		return fmt.Errorf("failed to set webhook %s: %w", webhookURL, err)
+	}
+
+	for {
+		// Telegram redelivers the updates the handler can't pass on in time
+		if dispatcher.Free() == 0 {
+			select {
+			case <-ctx.Done():
+			case <-dispatcher.Finished():
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				logger.Error("failed to shutdown webhook server", "error", err)
+			}
+			return nil
+		case err := <-serverErr:
+			return fmt.Errorf("webhook server failed: %w", err)
+		case update := <-updates:
+			dispatcher.Dispatch(update, bot)
+		}
+	}
+}
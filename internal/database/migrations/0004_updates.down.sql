@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS processed_updates;
+DROP TABLE IF EXISTS update_offsets;
\ No newline at end of file
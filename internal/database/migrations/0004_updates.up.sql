@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS update_offsets (
+  id UUID PRIMARY KEY DEFAULT uuidv7(),
+  bot_id BIGINT NOT NULL,
+  next_update_id BIGINT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE(bot_id)
+);
+
+CREATE TABLE IF NOT EXISTS processed_updates (
+  id UUID PRIMARY KEY DEFAULT uuidv7(),
+  bot_id BIGINT NOT NULL,
+  update_id BIGINT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE(bot_id, update_id)
+);
\ No newline at end of file
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/internal/router"
 	"github.com/ailinykh/reposter/v3/pkg/telegram"
 )
 
@@ -35,24 +36,36 @@ type Game struct {
 	repo GameRepository
 }
 
-func (g *Game) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
-	if u.Message == nil || len(u.Message.Commands()) == 0 {
-		return nil
-	}
-
-	command := u.Message.Commands()[0]
-	// command could be bot specific
-	before, _, found := strings.Cut(command, "@")
-	if found {
-		command = before
-	}
-
-	if !strings.HasPrefix(command, "/pidor") {
-		return nil
+func (g *Game) Register(r *router.Router) {
+	groupOnly := router.With(g.groupOnly)
+	menu := func(en, ru string) router.RouteOption {
+		return router.Menu(telegram.BotCommandScopeAllGroupChats, map[string]string{"": en, "ru": ru})
 	}
+	r.Command("pidorules", router.Message(g.rules), groupOnly,
+		menu("Game rules", "Правила игры"))
+	r.Command("pidoreg", router.Message(g.reg), groupOnly,
+		menu("Join the game", "Зарегистрироваться в игре"))
+	r.Command("pidorstats", router.Message(func(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
+		return g.stats(ctx, strconv.Itoa(time.Now().Year()), m, bot)
+	}), groupOnly,
+		menu("Top players of the year", "Статистика за текущий год"))
+	r.Command("pidorall", router.Message(g.all), groupOnly,
+		menu("Top players of all time", "Статистика за всё время"))
+	r.Command("pidorme", router.Message(g.me), groupOnly,
+		menu("Personal statistics", "Личная статистика"))
+	r.CommandPrefix("pidor", router.Message(func(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
+		matches := regexp.MustCompile(`^/pidor(\d+)(@\w+)?$`).FindAllStringSubmatch(m.Text, -1)
+		if len(matches) > 0 && len(matches[0]) > 1 {
+			return g.stats(ctx, matches[0][1], m, bot)
+		}
+		return g.play(ctx, m, bot)
+	}), groupOnly,
+		menu("Find out who is the one today", "Запустить розыгрыш"))
+}
 
-	if u.Message.Chat.Private() {
-		if u.Message.Chat.Type == "private" {
+func (g *Game) groupOnly(next router.HandlerFunc) router.HandlerFunc {
+	return func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+		if u.Message.Chat.Private() {
 			_, err := bot.SendMessage(ctx, &telegram.SendMessageParams{
 				ChatID:    u.Message.Chat.ID,
 				Text:      i18n("faggot_not_available_for_private"),
@@ -60,29 +73,9 @@ func (g *Game) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot
 			})
 			return err
 		}
-	}
 
-	g.l.Info("executing", "command", command)
-
-	switch command {
-	case "/pidorules":
-		return g.rules(ctx, u.Message, bot)
-	case "/pidoreg":
-		return g.reg(ctx, u.Message, bot)
-	case "/pidorstats":
-		return g.stats(ctx, strconv.Itoa(time.Now().Year()), u.Message, bot)
-	case "/pidorall":
-		return g.all(ctx, u.Message, bot)
-	case "/pidorme":
-		return g.me(ctx, u.Message, bot)
-	default:
-
-		matches := regexp.MustCompile(`^/pidor(\d+)$`).FindAllStringSubmatch(u.Message.Text, -1)
-		if len(matches) > 0 && len(matches[0]) > 1 {
-			return g.stats(ctx, matches[0][1], u.Message, bot)
-		} else {
-			return g.play(ctx, u.Message, bot)
-		}
+		g.l.Info("executing", "command", router.RouteName(ctx))
+		return next(ctx, u, bot)
 	}
 }
 
@@ -231,11 +224,23 @@ func (g *Game) me(ctx context.Context, m *telegram.Message, bot *telegram.Bot) e
 }
 
 var mutex sync.Mutex
+var locks = map[int64]*sync.Mutex{}
 
-func (g *Game) play(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
-	g.l.Info("game started", "chat_id", m.Chat.ID, "user_id", m.From.ID)
+// chatLock prevents simultaneous games in the same chat without blocking the others
+func chatLock(chatID int64) *sync.Mutex {
 	mutex.Lock()
 	defer mutex.Unlock()
+	if _, ok := locks[chatID]; !ok {
+		locks[chatID] = &sync.Mutex{}
+	}
+	return locks[chatID]
+}
+
+func (g *Game) play(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
+	g.l.Info("game started", "chat_id", m.Chat.ID, "user_id", m.From.ID)
+	lock := chatLock(m.Chat.ID)
+	lock.Lock()
+	defer lock.Unlock()
 
 	// TODO: chat settigs and bot menu
 
@@ -315,7 +320,11 @@ func (g *Game) play(ctx context.Context, m *telegram.Message, bot *telegram.Bot)
 		}
 
 		r := rand.IntN(3) + 1
-		time.Sleep(time.Duration(r) * time.Second)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(r) * time.Second):
+		}
 	}
 	return nil
 }
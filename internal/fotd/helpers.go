@@ -1,10 +1,10 @@
 package fotd
 
 import (
-	"fmt"
 	"sort"
 
 	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/format"
 )
 
 func calculateStatistics(rounds []repository.GetRoundsRow, filter func(repository.GetRoundsRow) bool) (map[string]int, []string) {
@@ -49,9 +49,9 @@ func displayName(p repository.GamePlayer) string {
 	return p.FirstName + " " + p.LastName
 }
 
-func mention(p repository.GamePlayer) string {
+func mention(p repository.GamePlayer) format.Node {
 	if p.Username != "" {
-		return "@" + p.Username
+		return format.Text("@" + p.Username)
 	}
-	return fmt.Sprintf(`<a href="tg://user?id=%d">%s %s</a>`, p.UserID, p.FirstName, p.LastName)
+	return format.Mention(p.UserID, p.FirstName+" "+p.LastName)
 }
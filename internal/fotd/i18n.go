@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"runtime"
 	"slices"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram/format"
 )
 
 var dict = map[string]string{
@@ -91,8 +93,17 @@ var dict = map[string]string{
 	"faggot_me": "%s, ты был(а) <b>пидором дня</b> — %d раз!",
 }
 
+// i18n escapes string arguments, so they can't break the markup
 func i18n(key string, args ...any) string {
 	if val, ok := dict[key]; ok {
+		for i, arg := range args {
+			switch v := arg.(type) {
+			case string:
+				args[i] = format.EscapeHTML(v)
+			case format.Node:
+				args[i] = format.HTML(v)
+			}
+		}
 		return fmt.Sprintf(val, args...)
 	}
 
@@ -0,0 +1,101 @@
+package hotlink
+
+import (
+	"context"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+// NewDirectExtractor sends the files linked directly, Telegram downloads them by itself
+func NewDirectExtractor(l *slog.Logger, client *http.Client) Extractor {
+	return &directExtractor{
+		l:      l,
+		client: client,
+	}
+}
+
+type directExtractor struct {
+	l      *slog.Logger
+	client *http.Client
+}
+
+func (e *directExtractor) Name() string {
+	return "direct"
+}
+
+func (e *directExtractor) Match(urlString string) bool {
+	u, err := url.Parse(urlString)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (e *directExtractor) Resolve(ctx context.Context, url string) (*Post, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		e.l.Error("failed to create HEAD request", "url", url, "error", err)
+		return nil, ErrURLNotSupported
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		e.l.Error("failed to perform HEAD request", "url", url, "error", err)
+		return nil, ErrURLNotSupported
+	}
+	res.Body.Close()
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		e.l.Error("content type not found", "url", url)
+		return nil, ErrURLNotSupported
+	}
+
+	e.l.Info("got contentType", "contentType", contentType)
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		e.l.Error("failed to parse content type", "content_type", contentType, "error", err)
+		return nil, ErrURLNotSupported
+	}
+
+	kind, emoji, ok := mediaKind(mediaType, params)
+	if !ok {
+		e.l.Info("unsupported content-type", "content_type", contentType)
+		return nil, ErrURLNotSupported
+	}
+
+	return &Post{
+		URL:   url,
+		Emoji: emoji,
+		Title: path.Base(url),
+		Items: []*Item{{Kind: kind, URL: url}},
+	}, nil
+}
+
+// mediaKind tells how to send the file of the media type, only the types
+// Telegram accepts by URL are supported https://core.telegram.org/bots/api#sending-files
+func mediaKind(mediaType string, params map[string]string) (MediaKind, string, bool) {
+	switch mediaType {
+	case "image/gif":
+		return MediaAnimation, "🎞", true
+	case "video/mp4":
+		return MediaVideo, "🔗", true
+	case "image/jpeg", "image/png", "image/webp":
+		return MediaPhoto, "🖼", true
+	case "audio/opus":
+		return MediaVoice, "🎤", true
+	case "audio/ogg":
+		// voice messages have to be encoded with opus, vorbis is not supported
+		if params["codecs"] == "opus" {
+			return MediaVoice, "🎤", true
+		}
+		return "", "", false
+	case "audio/mpeg", "audio/mp4", "audio/x-m4a":
+		return MediaAudio, "🎵", true
+	case "application/pdf", "application/zip":
+		return MediaDocument, "📄", true
+	default:
+		return "", "", false
+	}
+}
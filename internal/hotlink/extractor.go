@@ -0,0 +1,122 @@
+package hotlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+// Extractor turns a link into a media post
+type Extractor interface {
+	// Name identifies the extractor in the config and logs
+	Name() string
+	// Match reports whether the extractor could handle the link
+	Match(url string) bool
+	// Resolve fetches the post, ErrURLNotSupported passes the link to the next extractor
+	Resolve(ctx context.Context, url string) (*Post, error)
+}
+
+// Cacheable is implemented by the extractors giving their posts a Key,
+// only their links are offered for inline queries
+type Cacheable interface {
+	Cacheable() bool
+}
+
+type MediaKind string
+
+const (
+	MediaPhoto     MediaKind = "photo"
+	MediaVideo     MediaKind = "video"
+	MediaAnimation MediaKind = "animation"
+	MediaAudio     MediaKind = "audio"
+	MediaVoice     MediaKind = "voice"
+	MediaDocument  MediaKind = "document"
+)
+
+// Post is a normalized media post the link points to
+type Post struct {
+	// URL is the link the caption refers to
+	URL         string
+	Emoji       string
+	Title       string
+	Description string
+	Items       []*Item
+	// Key identifies the post regardless of the link, the posts having it are
+	// cached as videos and sent by file_id next time
+	Key string
+	// CaptionAbove shows the caption above the media
+	CaptionAbove bool
+}
+
+// Item is a single media of the post, it's sent either by URL or downloaded first
+type Item struct {
+	Kind MediaKind
+	// URL is the media link Telegram downloads by itself
+	URL       string
+	Thumbnail string
+	// Download fetches the media which Telegram can't get by URL
+	Download func(ctx context.Context) (*LocalMedia, error)
+	// Size is the approximate size of the media to download
+	Size     int64
+	Duration int
+	Width    int
+	Height   int
+}
+
+// LocalMedia is a downloaded item, it has to be disposed once sent
+type LocalMedia struct {
+	File      telegram.InputFile
+	Thumbnail telegram.InputFile
+	Dispose   func()
+}
+
+// NewRegistry tries the extractors in the given order
+func NewRegistry(l *slog.Logger, extractors ...Extractor) *Registry {
+	return &Registry{
+		l:          l,
+		extractors: extractors,
+	}
+}
+
+type Registry struct {
+	l          *slog.Logger
+	extractors []Extractor
+}
+
+// Resolve returns the post of the first extractor matching the link,
+// ErrURLNotSupported is returned when none of them can handle it
+func (r *Registry) Resolve(ctx context.Context, url string) (*Post, error) {
+	for _, e := range r.extractors {
+		if !e.Match(url) {
+			continue
+		}
+
+		r.l.Info("resolving url", "extractor", e.Name(), "url", url)
+		p, err := e.Resolve(ctx, url)
+		if errors.Is(err, ErrURLNotSupported) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		if len(p.Items) == 0 {
+			return nil, fmt.Errorf("%s: no media found at %s", e.Name(), url)
+		}
+		return p, nil
+	}
+	return nil, ErrURLNotSupported
+}
+
+// Cacheable reports whether any of the Cacheable extractors matches the link,
+// nothing is fetched
+func (r *Registry) Cacheable(url string) bool {
+	for _, e := range r.extractors {
+		if c, ok := e.(Cacheable); ok && c.Cacheable() && e.Match(url) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,11 +3,14 @@ package hotlink
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/internal/router"
 	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/format"
 	"github.com/ailinykh/reposter/v3/pkg/xcom"
 	"github.com/ailinykh/reposter/v3/pkg/ytdlp"
 )
@@ -15,64 +18,86 @@ import (
 type Repo interface {
 	Set(ctx context.Context, arg repository.SetParams) (repository.Cache, error)
 	Get(ctx context.Context, key string) (repository.Cache, error)
+	GetSettings(ctx context.Context, arg repository.GetSettingsParams) (repository.ChatSetting, error)
+	SetSettings(ctx context.Context, arg repository.SetSettingsParams) (repository.ChatSetting, error)
 }
 
-func New(l *slog.Logger, cache Repo, x *xcom.XComAPI, yd *ytdlp.YtDlp) *Handler {
+func New(l *slog.Logger, repo Repo, extractors *Registry) *Handler {
 	return &Handler{
-		l:     l,
-		cache: cache,
-		x:     x,
-		yd:    yd,
+		l:          l,
+		repo:       repo,
+		extractors: extractors,
 	}
 }
 
 type Handler struct {
-	l     *slog.Logger
-	cache Repo
-	x     *xcom.XComAPI
-	yd    *ytdlp.YtDlp
+	l          *slog.Logger
+	repo       Repo
+	extractors *Registry
+}
+
+func (h *Handler) Register(r *router.Router) {
+	r.On(router.KindInlineQuery, nil, func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+		return h.handleInline(ctx, u.InlineQuery, bot)
+	}, router.Name("hotlink_inline"))
+	r.On(router.KindMessage, func(u *telegram.Update) bool {
+		return len(u.Message.URLs()) > 0
+	}, h.Handle, router.Name("hotlink"), router.Timeout(10*time.Minute))
+	// reposts links in the channels the bot administers
+	r.On(router.KindChannelPost, func(u *telegram.Update) bool {
+		return len(u.ChannelPost.URLs()) > 0
+	}, router.ChannelPost(h.handleMessage), router.Name("hotlink_channel"), router.Timeout(10*time.Minute))
+	r.Command("overflow", router.Message(h.toggleOverflow), router.Menu(telegram.BotCommandScopeDefault, map[string]string{
+		"":   "Continue long captions in replies",
+		"ru": "Продолжать длинные подписи в ответах",
+	}))
 }
 
 func (h *Handler) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
-	// TODO: respect type="text_link" as well
-	if u.Message == nil || len(u.Message.URLs()) == 0 {
-		return nil
-	}
+	return h.handleMessage(ctx, u.Message, bot)
+}
 
+func (h *Handler) handleMessage(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
 	canNotifyUser := func(err error) error {
 		var tooLong *VideoTooLongError
 		if errors.As(err, &tooLong) {
-			return fmt.Errorf("%s\n<b>⏳ video too long: %d sec</b>", tooLong.Title, tooLong.Duration)
+			return errors.New(format.HTML(
+				format.Text(tooLong.Title+"\n"),
+				format.Bold(format.Textf("⏳ video too long: %d sec", tooLong.Duration)),
+			))
 		}
 
 		var xErr *xcom.Error
 		if errors.As(err, &xErr) {
-			return fmt.Errorf("😬 %s", xErr.Error())
+			return errors.New(format.HTML(format.Textf("😬 %s", xErr.Error())))
 		}
 
 		var ytErr *ytdlp.Error
 		if errors.As(err, &ytErr) {
-			return fmt.Errorf("😬 %s", ytErr.Error())
+			return errors.New(format.HTML(format.Textf("😬 %s", ytErr.Error())))
 		}
 
 		return nil
 	}
 
-	for _, urlString := range u.Message.URLs() {
-		if err := h.handleSocial(ctx, urlString, u.Message, bot); err != nil {
+	for _, urlString := range m.URLs() {
+		if err := h.handleURL(ctx, urlString, m, bot); err != nil {
 			if errors.Is(err, ErrURLNotSupported) {
-				return h.handleHotlink(ctx, urlString, u.Message, bot)
+				h.l.Info("url not supported yet", "url", urlString)
+				continue
 			}
 
 			if e := canNotifyUser(err); e != nil {
+				reply := &telegram.ReplyParameters{MessageID: m.ID}
+				// the link of a `text_link` entity is not a part of the text
+				if strings.Contains(m.Text, urlString) || strings.Contains(m.Caption, urlString) {
+					reply.Quote = urlString
+				}
 				_, _ = bot.SendMessage(ctx, &telegram.SendMessageParams{
-					ChatID:    u.Message.Chat.ID,
-					Text:      e.Error(),
-					ParseMode: telegram.ParseModeHTML,
-					ReplyParameters: &telegram.ReplyParameters{
-						MessageID: u.Message.ID,
-						Quote:     urlString,
-					},
+					ChatID:          m.Chat.ID,
+					Text:            e.Error(),
+					ParseMode:       telegram.ParseModeHTML,
+					ReplyParameters: reply,
 				})
 			} else {
 				h.l.Error("failed to process url", "error", err, "url", urlString)
@@ -83,3 +108,35 @@ func (h *Handler) Handle(ctx context.Context, u *telegram.Update, bot *telegram.
 
 	return nil
 }
+
+func (h *Handler) handleURL(ctx context.Context, urlString string, m *telegram.Message, bot *telegram.Bot) error {
+	h.l.Info("processing url", "url", urlString)
+
+	p, err := h.extractors.Resolve(ctx, urlString)
+	if err != nil {
+		return err
+	}
+
+	if err = h.sendPost(ctx, p, m, bot); err != nil {
+		return err
+	}
+
+	if p.Key != "" {
+		if err = h.cacheLink(urlString, p); err != nil {
+			h.l.Error("failed to cache link", "url", urlString, "error", err)
+		}
+	}
+	return nil
+}
+
+// author returns the name of the message sender, channel posts have no `from`
+func author(m *telegram.Message) string {
+	switch {
+	case m.From != nil:
+		return m.From.DisplayName()
+	case m.SenderChat != nil:
+		return m.SenderChat.Title
+	default:
+		return m.Chat.Title
+	}
+}
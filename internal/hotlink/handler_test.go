@@ -0,0 +1,255 @@
+package hotlink_test
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ailinykh/reposter/v3/internal/hotlink"
+	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/internal/router"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/telegramtest"
+)
+
+func TestPhoto(t *testing.T) {
+	server, bot, r := setup(t, &extractor{post: &hotlink.Post{
+		Emoji: "🖼",
+		Title: "Photo",
+		Items: []*hotlink.Item{{Kind: hotlink.MediaPhoto, URL: "https://example.com/photo.jpg"}},
+	}})
+
+	dispatch(t, r, bot, "look https://example.com/post")
+
+	calls := server.Calls("sendPhoto")
+	if len(calls) != 1 {
+		t.Fatalf("sendPhoto calls = %d, want 1", len(calls))
+	}
+	if photo := calls[0].String("photo"); photo != "https://example.com/photo.jpg" {
+		t.Errorf("photo = %q", photo)
+	}
+	if caption := calls[0].String("caption"); !strings.Contains(caption, "Photo") || !strings.Contains(caption, "John") {
+		t.Errorf("caption = %q", caption)
+	}
+}
+
+func TestAlbum(t *testing.T) {
+	server, bot, r := setup(t, &extractor{post: &hotlink.Post{
+		Emoji: "🖼",
+		Title: "Album",
+		Items: []*hotlink.Item{
+			{Kind: hotlink.MediaPhoto, URL: "https://example.com/1.jpg"},
+			{Kind: hotlink.MediaVideo, URL: "https://example.com/2.mp4"},
+		},
+	}})
+
+	dispatch(t, r, bot, "https://example.com/post")
+
+	calls := server.Calls("sendMediaGroup")
+	if len(calls) != 1 {
+		t.Fatalf("sendMediaGroup calls = %d, want 1", len(calls))
+	}
+	var media []map[string]any
+	if err := calls[0].Decode("media", &media); err != nil {
+		t.Fatal(err)
+	}
+	if len(media) != 2 || media[0]["type"] != "photo" || media[1]["type"] != "video" {
+		t.Errorf("media = %v", media)
+	}
+}
+
+func TestCachedVideo(t *testing.T) {
+	server, bot, r := setup(t, &extractor{post: &hotlink.Post{
+		Emoji: "🎞",
+		Title: "Video",
+		Key:   "test.id.1",
+		Items: []*hotlink.Item{{Kind: hotlink.MediaVideo, URL: "https://example.com/video.mp4"}},
+	}})
+
+	dispatch(t, r, bot, "https://example.com/post")
+	dispatch(t, r, bot, "https://example.com/post")
+
+	calls := server.Calls("sendVideo")
+	if len(calls) != 2 {
+		t.Fatalf("sendVideo calls = %d, want 2", len(calls))
+	}
+	if video := calls[0].String("video"); video != "https://example.com/video.mp4" {
+		t.Errorf("first video = %q", video)
+	}
+	// the fake server names files after the message they were sent with
+	if video := calls[1].String("video"); video != "video-1" {
+		t.Errorf("second video = %q, want the file_id of the first one", video)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	server, bot, r := setup(t, &extractor{})
+
+	dispatch(t, r, bot, "https://example.com/post")
+
+	if calls := server.Calls(); len(calls) != 0 {
+		t.Errorf("calls = %v, want none", calls)
+	}
+}
+
+func TestInline(t *testing.T) {
+	post := &hotlink.Post{
+		Emoji: "🎞",
+		Title: "Video",
+		Key:   "test.id.1",
+		Items: []*hotlink.Item{{Kind: hotlink.MediaVideo, URL: "https://example.com/video.mp4"}},
+	}
+
+	tests := []struct {
+		name      string
+		cacheable bool
+		reposted  bool
+		result    string
+	}{
+		{"not cacheable", false, false, ""},
+		{"not reposted", true, false, "article"},
+		{"reposted", true, true, "video"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &extractor{post: post, cacheable: tt.cacheable}
+			server, bot, r := setup(t, e)
+			if tt.reposted {
+				dispatch(t, r, bot, "https://example.com/post")
+			}
+			resolved := e.resolved
+
+			_, err := r.Dispatch(t.Context(), &telegram.Update{ID: 1, InlineQuery: &telegram.InlineQuery{
+				ID:    "1",
+				From:  &telegram.User{ID: 1, FirstName: "John"},
+				Query: "https://example.com/post",
+			}}, bot)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// inline queries never reach the extractors
+			if e.resolved != resolved {
+				t.Errorf("resolved %d times by inline query", e.resolved-resolved)
+			}
+			calls := server.Calls("answerInlineQuery")
+			if len(calls) != 1 {
+				t.Fatalf("answerInlineQuery calls = %d, want 1", len(calls))
+			}
+			var results []map[string]any
+			if err := calls[0].Decode("results", &results); err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.result == "" && len(results) != 0:
+				t.Errorf("results = %v, want none", results)
+			case tt.result != "" && (len(results) != 1 || results[0]["type"] != tt.result):
+				t.Errorf("results = %v, want one %s", results, tt.result)
+			}
+		})
+	}
+}
+
+func setup(t *testing.T, e *extractor) (*telegramtest.Server, *telegram.Bot, *router.Router) {
+	t.Helper()
+
+	server := telegramtest.NewServer()
+	t.Cleanup(server.Close)
+
+	bot, err := server.NewBot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Reset()
+
+	l := slog.New(slog.DiscardHandler)
+	r := router.New()
+	hotlink.New(l, &repo{cache: map[string]repository.Cache{}}, hotlink.NewRegistry(l, e)).Register(r)
+	return server, bot, r
+}
+
+func dispatch(t *testing.T, r *router.Router, bot *telegram.Bot, text string) {
+	t.Helper()
+
+	m := &telegram.Message{
+		ID:   1,
+		Text: text,
+		Chat: &telegram.Chat{ID: 1, Type: "private"},
+		From: &telegram.User{ID: 1, FirstName: "John"},
+	}
+	offset := 0
+	for word := range strings.SplitSeq(text, " ") {
+		if strings.HasPrefix(word, "https://") {
+			m.Entities = append(m.Entities, telegram.MessageEntity{Type: "url", Offset: offset, Length: len(word)})
+		}
+		offset += len(word) + 1
+	}
+
+	if _, err := r.Dispatch(t.Context(), &telegram.Update{ID: 1, Message: m}, bot); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// extractor resolves every url to the post, or doesn't support any without one
+type extractor struct {
+	post      *hotlink.Post
+	cacheable bool
+	resolved  int
+}
+
+func (e *extractor) Cacheable() bool {
+	return e.cacheable
+}
+
+func (e *extractor) Name() string {
+	return "test"
+}
+
+func (e *extractor) Match(url string) bool {
+	return true
+}
+
+func (e *extractor) Resolve(ctx context.Context, url string) (*hotlink.Post, error) {
+	e.resolved++
+	if e.post == nil {
+		return nil, hotlink.ErrURLNotSupported
+	}
+	p := *e.post
+	p.URL = url
+	return &p, nil
+}
+
+type repo struct {
+	mu    sync.Mutex
+	cache map[string]repository.Cache
+}
+
+func (r *repo) Set(ctx context.Context, arg repository.SetParams) (repository.Cache, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := repository.Cache{Key: arg.Key, Value: arg.Value}
+	r.cache[arg.Key] = c
+	return c, nil
+}
+
+func (r *repo) Get(ctx context.Context, key string) (repository.Cache, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.cache[key]
+	if !ok {
+		return c, sql.ErrNoRows
+	}
+	return c, nil
+}
+
+func (r *repo) GetSettings(ctx context.Context, arg repository.GetSettingsParams) (repository.ChatSetting, error) {
+	return repository.ChatSetting{}, sql.ErrNoRows
+}
+
+func (r *repo) SetSettings(ctx context.Context, arg repository.SetSettingsParams) (repository.ChatSetting, error) {
+	return repository.ChatSetting{ChatID: arg.ChatID, Key: arg.Key, Value: arg.Value}, nil
+}
@@ -1,50 +0,0 @@
-package hotlink
-
-import (
-	"context"
-	"fmt"
-	"net/http"
-	"path"
-	"strings"
-
-	"github.com/ailinykh/reposter/v3/pkg/telegram"
-)
-
-func (h *Handler) handleHotlink(ctx context.Context, url string, m *telegram.Message, bot *telegram.Bot) error {
-	res, err := http.DefaultClient.Head(url)
-	if err != nil {
-		h.l.Error("failed to perform HEAD request", "url", url, "error", err)
-		return nil
-	}
-
-	contentType := res.Header.Get("Content-Type")
-	if contentType == "" {
-		h.l.Error("content type not found", "url", url)
-		return nil
-	}
-
-	h.l.Info("got contentType", "contentType", contentType)
-
-	if strings.HasPrefix(contentType, "video") {
-		_, err = bot.SendVideo(ctx, &telegram.SendVideoParams{
-			ChatID:    m.Chat.ID,
-			Video:     telegram.InputFileURL(url),
-			Caption:   fmt.Sprintf(`<a href="%s">🔗</a> <b>%s</b> <i>(by %s)</i>`, url, path.Base(url), m.From.DisplayName()),
-			ParseMode: telegram.ParseModeHTML,
-		})
-		return err
-	}
-
-	if strings.HasPrefix(contentType, "image") {
-		_, err = bot.SendPhoto(ctx, &telegram.SendPhotoParams{
-			ChatID:    m.Chat.ID,
-			Photo:     telegram.InputFileURL(url),
-			Caption:   fmt.Sprintf(`<a href="%s">🖼</a> <b>%s</b> <i>(by %s)</i>`, url, path.Base(url), m.From.DisplayName()),
-			ParseMode: telegram.ParseModeHTML,
-		})
-		return err
-	}
-
-	h.l.Info("unsupported content-type", "content_type", contentType)
-	return nil
-}
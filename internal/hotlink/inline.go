@@ -0,0 +1,109 @@
+package hotlink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+// inlinePost is what an inline query needs to offer a post reposted already
+type inlinePost struct {
+	URL         string `json:"url"`
+	Emoji       string `json:"emoji"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Key         string `json:"key"`
+}
+
+// handleInline offers a video which has been reposted already, so it could be
+// shared in any chat, even the ones where the bot is not a member.
+// Every keystroke is a query, so the link is only looked up in the cache.
+func (h *Handler) handleInline(ctx context.Context, q *telegram.InlineQuery, bot *telegram.Bot) error {
+	params := &telegram.AnswerInlineQueryParams{
+		InlineQueryID: q.ID,
+		Results:       []telegram.InlineQueryResult{},
+		CacheTime:     300,
+	}
+
+	urlString := strings.TrimSpace(q.Query)
+	if !h.extractors.Cacheable(urlString) {
+		return bot.AnswerInlineQuery(ctx, params)
+	}
+
+	h.l.Info("processing inline query", "url", urlString)
+
+	if ip, err := h.cachedLink(ctx, urlString); err == nil {
+		p := &Post{
+			URL:         ip.URL,
+			Emoji:       ip.Emoji,
+			Title:       ip.Title,
+			Description: ip.Description,
+			Key:         ip.Key,
+		}
+		if videos, err := h.cachedVideos(ctx, cacheKey(p, bot.Username)); err == nil {
+			params.Results = append(params.Results, &telegram.InlineQueryResultCachedVideo{
+				Type:        "video",
+				ID:          p.Key,
+				VideoFileID: videos[0].FileID,
+				Title:       p.Title,
+				Caption:     makeCaption(p, q.From.DisplayName()),
+				ParseMode:   telegram.ParseModeHTML,
+			})
+			return bot.AnswerInlineQuery(ctx, params)
+		}
+	}
+
+	// The link goes to the chat as a regular message,
+	// so the bot downloads and sends the video if it's a member of the chat
+	params.Results = append(params.Results, &telegram.InlineQueryResultArticle{
+		Type:        "article",
+		ID:          "download",
+		Title:       urlString,
+		Description: "⬇️ download and send",
+		InputMessageContent: &telegram.InputTextMessageContent{
+			MessageText: urlString,
+		},
+	})
+	return bot.AnswerInlineQuery(ctx, params)
+}
+
+func linkKey(url string) string {
+	return "hotlink.link." + url
+}
+
+// cacheLink remembers the post behind the link for inline queries
+func (h *Handler) cacheLink(url string, p *Post) error {
+	data, err := json.Marshal(&inlinePost{
+		URL:         p.URL,
+		Emoji:       p.Emoji,
+		Title:       p.Title,
+		Description: p.Description,
+		Key:         p.Key,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode post: %w", err)
+	}
+
+	_, err = h.repo.Set(context.Background(), repository.SetParams{
+		Key:   linkKey(url),
+		Value: data,
+	})
+	return err
+}
+
+func (h *Handler) cachedLink(ctx context.Context, url string) (*inlinePost, error) {
+	cache, err := h.repo.Get(ctx, linkKey(url))
+	if err != nil {
+		return nil, err
+	}
+
+	var p inlinePost
+	if err = json.Unmarshal(cache.Value, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,125 @@
+package hotlink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/format"
+)
+
+const overflowSettingsKey = "hotlink.overflow"
+
+type overflowSettings struct {
+	Enabled bool `json:"enabled"`
+}
+
+// splitCaption fits the text into the caption after the header. When the chat has
+// the overflow mode enabled, the text which doesn't fit is returned to be sent as replies.
+func (h *Handler) splitCaption(ctx context.Context, chatID int64, header []format.Node, text string) (string, []string) {
+	nodes := slices.Concat(header, []format.Node{format.Text(text)})
+	if format.Len(nodes...) <= format.CaptionLimit || !h.overflowEnabled(ctx, chatID) {
+		return format.Caption(nodes...), nil
+	}
+
+	head, tail := format.Cut(text, format.CaptionLimit-format.Len(header...))
+	caption := format.Caption(slices.Concat(header, []format.Node{format.Text(head)})...)
+	return caption, format.Split(tail, format.MessageLimit)
+}
+
+// sendOverflow sends the rest of the caption as a thread of replies to the media
+func (h *Handler) sendOverflow(ctx context.Context, replyTo *telegram.Message, overflow []string, bot *telegram.Bot) error {
+	for _, text := range overflow {
+		m, err := bot.SendMessage(ctx, &telegram.SendMessageParams{
+			ChatID:    replyTo.Chat.ID,
+			Text:      format.HTML(format.Text(text)),
+			ParseMode: telegram.ParseModeHTML,
+			LinkPreviewOptions: &telegram.LinkPreviewOptions{
+				IsDisabled: true,
+			},
+			ReplyParameters: &telegram.ReplyParameters{
+				MessageID: replyTo.ID,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send overflow: %w", err)
+		}
+		replyTo = m
+	}
+	return nil
+}
+
+func (h *Handler) overflowEnabled(ctx context.Context, chatID int64) bool {
+	data, err := h.repo.GetSettings(ctx, repository.GetSettingsParams{
+		ChatID: chatID,
+		Key:    overflowSettingsKey,
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			h.l.Error("failed to get settings", "chat_id", chatID, "error", err)
+		}
+		return false
+	}
+
+	var settings overflowSettings
+	if err = json.Unmarshal(data.Value, &settings); err != nil {
+		h.l.Error("failed to unmarshal settings", "chat_id", chatID, "error", err)
+		return false
+	}
+
+	return settings.Enabled
+}
+
+// toggleOverflow switches the overflow mode of the chat, only admins can do it in groups
+func (h *Handler) toggleOverflow(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
+	if !m.Chat.Private() {
+		member, err := bot.GetChatMember(ctx, &telegram.GetChatMemberParams{
+			ChatID: m.Chat.ID,
+			UserID: m.From.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get chat member: %w", err)
+		}
+
+		if !member.Administrator() {
+			_, err = bot.SendMessage(ctx, &telegram.SendMessageParams{
+				ChatID:          m.Chat.ID,
+				Text:            "🚫 only admins can change this setting",
+				ReplyParameters: &telegram.ReplyParameters{MessageID: m.ID},
+			})
+			return err
+		}
+	}
+
+	settings := overflowSettings{Enabled: !h.overflowEnabled(ctx, m.Chat.ID)}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	if _, err = h.repo.SetSettings(ctx, repository.SetSettingsParams{
+		ChatID: m.Chat.ID,
+		Key:    overflowSettingsKey,
+		Value:  data,
+	}); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+
+	h.l.Info("caption overflow toggled", "chat_id", m.Chat.ID, "enabled", settings.Enabled)
+
+	text := "📜 long captions are cut now"
+	if settings.Enabled {
+		text = "📜 long captions continue in replies now"
+	}
+	_, err = bot.SendMessage(ctx, &telegram.SendMessageParams{
+		ChatID:          m.Chat.ID,
+		Text:            text,
+		ReplyParameters: &telegram.ReplyParameters{MessageID: m.ID},
+	})
+	return err
+}
@@ -0,0 +1,332 @@
+package hotlink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/format"
+)
+
+// sendPost sends the media of the post to the chat of the message,
+// the cached ones go by file_id without downloading anything
+func (h *Handler) sendPost(ctx context.Context, p *Post, m *telegram.Message, bot *telegram.Bot) error {
+	caption, overflow := h.splitCaption(ctx, m.Chat.ID, captionHeader(p, author(m)), p.Description)
+	key := cacheKey(p, bot.Username)
+	if key != "" {
+		sent, err := h.sendCached(ctx, key, caption, p, m.Chat.ID, bot)
+		if err == nil {
+			return h.sendOverflow(ctx, sent, overflow, bot)
+		}
+		h.l.Error("failed to send by file_id", "key", key, "error", err)
+	}
+
+	var size int64
+	for _, item := range p.Items {
+		if item.Download != nil {
+			size += item.Size
+		}
+	}
+
+	if size > bot.UploadLimit() {
+		h.l.Warn("video too long", "url", p.URL, "size", size, "duration", p.Items[0].Duration)
+		if !m.Chat.Private() {
+			return nil // be silent in group chat
+		}
+		return &VideoTooLongError{
+			Duration: time.Duration(p.Items[0].Duration),
+			Title:    p.Title,
+		}
+	}
+
+	files, dispose, err := download(ctx, p.Items)
+	if err != nil {
+		return err
+	}
+	defer dispose()
+
+	messages, err := sendMedia(ctx, p, files, caption, m.Chat.ID, bot)
+	if err != nil {
+		return fmt.Errorf("failed to send media: %w", err)
+	}
+
+	h.l.Info("post sent successfully", "url", p.URL, "count", len(messages), "size", size)
+
+	// the caption goes with the first item
+	if err := h.sendOverflow(ctx, messages[0], overflow, bot); err != nil {
+		h.l.Error("failed to send caption overflow", "error", err)
+	}
+
+	if key == "" {
+		return nil
+	}
+	return h.cacheVideos(key, messages, len(p.Items))
+}
+
+func makeCaption(p *Post, author string) string {
+	return format.Caption(append(captionHeader(p, author), format.Text(p.Description))...)
+}
+
+func captionHeader(p *Post, author string) []format.Node {
+	nodes := []format.Node{
+		format.Link(p.URL, format.Text(p.Emoji)),
+		format.Text(" "),
+		format.Bold(format.Text(p.Title)),
+		format.Text(" "),
+		format.Italic(format.Textf("(by %s)", author)),
+	}
+	if p.Description != "" {
+		nodes = append(nodes, format.Text("\n\n"))
+	}
+	return nodes
+}
+
+// cacheKey is empty for the posts which are not cached
+func cacheKey(p *Post, username string) string {
+	if p.Key == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s.bot.%s.videos", p.Key, username)
+}
+
+// file is an item ready to be sent
+type file struct {
+	*Item
+	media     telegram.InputFile
+	thumbnail telegram.InputFile
+}
+
+// download fetches the items Telegram can't get by URL, the files have to be disposed once sent
+func download(ctx context.Context, items []*Item) ([]file, func(), error) {
+	var disposers []func()
+	dispose := func() {
+		for _, d := range disposers {
+			d()
+		}
+	}
+
+	files := make([]file, len(items))
+	for i, item := range items {
+		f := file{Item: item, media: telegram.InputFileURL(item.URL)}
+		if item.Thumbnail != "" {
+			f.thumbnail = telegram.InputFileURL(item.Thumbnail)
+		}
+
+		if item.Download != nil {
+			local, err := item.Download(ctx)
+			if err != nil {
+				dispose()
+				return nil, nil, fmt.Errorf("failed to download item %d: %w", i+1, err)
+			}
+			disposers = append(disposers, local.Dispose)
+			f.media = local.File
+			if local.Thumbnail != nil {
+				f.thumbnail = local.Thumbnail
+			}
+		}
+		files[i] = f
+	}
+
+	return files, dispose, nil
+}
+
+func sendMedia(ctx context.Context, p *Post, files []file, caption string, chatID int64, bot *telegram.Bot) ([]*telegram.Message, error) {
+	if len(files) > 1 {
+		media := make([]telegram.InputMedia, len(files))
+		for i, f := range files {
+			if i > 0 {
+				caption = ""
+			}
+			media[i] = inputMedia(f, caption, p.CaptionAbove)
+		}
+		return bot.SendMediaGroup(ctx, &telegram.SendMediaGroupParams{
+			ChatID: chatID,
+			Media:  media,
+		})
+	}
+
+	m, err := sendFile(ctx, files[0], caption, p.CaptionAbove, chatID, bot)
+	if err != nil {
+		return nil, err
+	}
+	return []*telegram.Message{m}, nil
+}
+
+func sendFile(ctx context.Context, f file, caption string, above bool, chatID int64, bot *telegram.Bot) (*telegram.Message, error) {
+	switch f.Kind {
+	case MediaPhoto:
+		return bot.SendPhoto(ctx, &telegram.SendPhotoParams{
+			ChatID:                chatID,
+			Photo:                 f.media,
+			Caption:               caption,
+			ParseMode:             telegram.ParseModeHTML,
+			ShowCaptionAboveMedia: above,
+		})
+	case MediaVideo:
+		return bot.SendVideo(ctx, &telegram.SendVideoParams{
+			ChatID:                chatID,
+			Video:                 f.media,
+			Duration:              f.Duration,
+			Width:                 f.Width,
+			Height:                f.Height,
+			Thumbnail:             f.thumbnail,
+			Caption:               caption,
+			ParseMode:             telegram.ParseModeHTML,
+			ShowCaptionAboveMedia: above,
+			SupportsStreaming:     true,
+		})
+	case MediaAnimation:
+		return bot.SendAnimation(ctx, &telegram.SendAnimationParams{
+			ChatID:                chatID,
+			Animation:             f.media,
+			Duration:              f.Duration,
+			Width:                 f.Width,
+			Height:                f.Height,
+			Thumbnail:             f.thumbnail,
+			Caption:               caption,
+			ParseMode:             telegram.ParseModeHTML,
+			ShowCaptionAboveMedia: above,
+		})
+	case MediaAudio:
+		return bot.SendAudio(ctx, &telegram.SendAudioParams{
+			ChatID:    chatID,
+			Audio:     f.media,
+			Caption:   caption,
+			ParseMode: telegram.ParseModeHTML,
+			Duration:  f.Duration,
+			Thumbnail: f.thumbnail,
+		})
+	case MediaVoice:
+		return bot.SendVoice(ctx, &telegram.SendVoiceParams{
+			ChatID:    chatID,
+			Voice:     f.media,
+			Caption:   caption,
+			ParseMode: telegram.ParseModeHTML,
+			Duration:  f.Duration,
+		})
+	default:
+		return bot.SendDocument(ctx, &telegram.SendDocumentParams{
+			ChatID:    chatID,
+			Document:  f.media,
+			Thumbnail: f.thumbnail,
+			Caption:   caption,
+			ParseMode: telegram.ParseModeHTML,
+		})
+	}
+}
+
+// inputMedia makes an album item, animations go as videos since albums can't have them
+func inputMedia(f file, caption string, above bool) telegram.InputMedia {
+	var parseMode telegram.ParseMode
+	if caption != "" {
+		parseMode = telegram.ParseModeHTML
+	}
+
+	switch f.Kind {
+	case MediaPhoto:
+		return &telegram.InputMediaPhoto{
+			Type:                  "photo",
+			Media:                 f.media,
+			Caption:               caption,
+			ParseMode:             parseMode,
+			ShowCaptionAboveMedia: above,
+		}
+	case MediaVideo, MediaAnimation:
+		return &telegram.InputMediaVideo{
+			Type:                  "video",
+			Media:                 f.media,
+			Thumbnail:             f.thumbnail,
+			Caption:               caption,
+			ParseMode:             parseMode,
+			ShowCaptionAboveMedia: above,
+			Width:                 f.Width,
+			Height:                f.Height,
+			Duration:              f.Duration,
+			SupportsStreaming:     true,
+		}
+	case MediaAudio, MediaVoice:
+		return &telegram.InputMediaAudio{
+			Type:      "audio",
+			Media:     f.media,
+			Thumbnail: f.thumbnail,
+			Caption:   caption,
+			ParseMode: parseMode,
+			Duration:  f.Duration,
+		}
+	default:
+		return &telegram.InputMediaDocument{
+			Type:      "document",
+			Media:     f.media,
+			Thumbnail: f.thumbnail,
+			Caption:   caption,
+			ParseMode: parseMode,
+		}
+	}
+}
+
+func (h *Handler) sendCached(ctx context.Context, key, caption string, p *Post, chatID int64, bot *telegram.Bot) (*telegram.Message, error) {
+	videos, err := h.cachedVideos(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]file, len(videos))
+	for i, v := range videos {
+		files[i] = file{
+			Item:  &Item{Kind: MediaVideo},
+			media: telegram.InputFileURL(v.FileID),
+		}
+	}
+
+	messages, err := sendMedia(ctx, p, files, caption, chatID, bot)
+	if err != nil {
+		return nil, err
+	}
+	return messages[0], nil
+}
+
+func (h *Handler) cachedVideos(ctx context.Context, key string) ([]*telegram.Video, error) {
+	cache, err := h.repo.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var videos []*telegram.Video
+	if err = json.Unmarshal(cache.Value, &videos); err != nil {
+		return nil, err
+	}
+
+	if len(videos) == 0 {
+		return nil, fmt.Errorf("no videos found by key %s", key)
+	}
+
+	h.l.Info("got videos from cache", "key", key, "count", len(videos))
+	return videos, nil
+}
+
+func (h *Handler) cacheVideos(key string, messages []*telegram.Message, expected int) error {
+	var videos []*telegram.Video
+	for _, message := range messages {
+		if message.Video != nil {
+			videos = append(videos, message.Video)
+		}
+	}
+
+	if len(videos) != expected {
+		return fmt.Errorf("expected %d videos in outgoing messages, got %d", expected, len(videos))
+	}
+
+	data, err := json.Marshal(videos)
+	if err != nil {
+		return fmt.Errorf("failed to encode videos: %w", err)
+	}
+
+	_, err = h.repo.Set(context.Background(), repository.SetParams{
+		Key:   key,
+		Value: data,
+	})
+	return err
+}
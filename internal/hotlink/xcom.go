@@ -3,77 +3,85 @@ package hotlink
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"regexp"
 
-	"github.com/ailinykh/reposter/v3/pkg/telegram"
 	"github.com/ailinykh/reposter/v3/pkg/xcom"
 )
 
-func (h *Handler) handleXcom(ctx context.Context, urlString string, m *telegram.Message, bot *telegram.Bot) error {
-	r := regexp.MustCompile(`https://(?i:twitter|x)\.com\S+/status/(\d+)`)
-	match := r.FindStringSubmatch(urlString)
+var tweetURL = regexp.MustCompile(`https://(?i:twitter|x)\.com\S+/status/(\d+)`)
 
-	if len(match) < 2 {
-		h.l.Warn("can't find tweet id", "url", urlString)
-		return nil
+// NewXcomExtractor sends the media of a tweet by the links Telegram downloads by itself
+func NewXcomExtractor(l *slog.Logger, x *xcom.XComAPI) Extractor {
+	return &xcomExtractor{
+		l: l,
+		x: x,
 	}
+}
 
-	tweet, err := h.x.Get(ctx, match[1])
-	if err != nil {
-		h.l.Error("failed to get tweet", "error", err)
-		return err
+type xcomExtractor struct {
+	l *slog.Logger
+	x *xcom.XComAPI
+}
+
+func (e *xcomExtractor) Name() string {
+	return "xcom"
+}
+
+func (e *xcomExtractor) Match(url string) bool {
+	return tweetURL.MatchString(url)
+}
+
+func (e *xcomExtractor) Resolve(ctx context.Context, url string) (*Post, error) {
+	match := tweetURL.FindStringSubmatch(url)
+	if len(match) < 2 {
+		e.l.Warn("can't find tweet id", "url", url)
+		return nil, ErrURLNotSupported
 	}
 
-	params := &telegram.SendMediaGroupParams{
-		ChatID: m.Chat.ID,
-		Media:  []telegram.InputMedia{},
+	tweet, err := e.x.Get(ctx, match[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tweet: %w", err)
 	}
 
-	var caption string
+	text := tweet.Legacy.FullText
 	if tweet.NoteTweet != nil {
-		caption = fmt.Sprintf("<a href='%s'>🐦</a> <b>%s</b> <i>(by %s)</i>\n%s", urlString, tweet.Core.UserResults.Result.Core.Name, m.From.DisplayName(), tweet.NoteTweet.NoteTweetResults.Result.Text)
+		text = tweet.NoteTweet.NoteTweetResults.Result.Text
 	} else {
-		re := regexp.MustCompile(`\s?http\S+$`)
-		text := re.ReplaceAllString(tweet.Legacy.FullText, "")
-		caption = fmt.Sprintf("<a href='%s'>🐦</a> <b>%s</b> <i>(by %s)</i>\n%s", urlString, tweet.Core.UserResults.Result.Core.Name, m.From.DisplayName(), text)
+		text = regexp.MustCompile(`\s?http\S+$`).ReplaceAllString(text, "")
+	}
+
+	p := &Post{
+		URL:          url,
+		Emoji:        "🐦",
+		Title:        tweet.Core.UserResults.Result.Core.Name,
+		Description:  text,
+		CaptionAbove: true,
 	}
 
-	for i, m := range tweet.Legacy.Entities.Media {
+	for _, m := range tweet.Legacy.Entities.Media {
 		switch m.Type {
 		case "photo":
-			photo := &telegram.InputMediaPhoto{
-				Type:                  "photo",
-				Media:                 m.MediaUrlHttps,
-				ParseMode:             telegram.ParseModeHTML,
-				ShowCaptionAboveMedia: true,
-			}
-			if i == len(tweet.Legacy.Entities.Media)-1 {
-				photo.Caption = caption
-			}
-			params.Media = append(params.Media, photo)
+			p.Items = append(p.Items, &Item{
+				Kind: MediaPhoto,
+				URL:  m.MediaUrlHttps,
+			})
 		case "video", "animated_gif":
-			video := &telegram.InputMediaVideo{
-				Type:                  "video",
-				Media:                 m.VideoInfo.Best().URL,
-				Duration:              int(m.VideoInfo.Duration / 1000),
-				Thumbnail:             m.MediaUrlHttps,
-				ParseMode:             telegram.ParseModeHTML,
-				ShowCaptionAboveMedia: true,
-			}
-			if i == len(tweet.Legacy.Entities.Media)-1 {
-				video.Caption = caption
-			}
-			params.Media = append(params.Media, video)
+			p.Items = append(p.Items, &Item{
+				Kind:      MediaVideo,
+				URL:       m.VideoInfo.Best().URL,
+				Thumbnail: m.MediaUrlHttps,
+				Duration:  int(m.VideoInfo.Duration / 1000),
+			})
 		default:
-			h.l.Error("unexpected media type", "type", m.Type, "tweet", tweet.RestID)
+			e.l.Error("unexpected media type", "type", m.Type, "tweet", tweet.RestID)
 		}
 	}
 
-	if len(params.Media) == 0 {
-		h.l.Warn("no media found", "url", urlString)
-		return &xcom.Error{TypeName: "No media found.", Reason: "Only tweets with media supported at the moment"}
+	if len(p.Items) == 0 {
+		e.l.Warn("no media found", "url", url)
+		return nil, &xcom.Error{TypeName: "No media found.", Reason: "Only tweets with media supported at the moment"}
 	}
 
-	_, err = bot.SendMediaGroup(ctx, params)
-	return nil
+	return p, nil
 }
@@ -0,0 +1,176 @@
+package hotlink
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ailinykh/reposter/v3/pkg/ffmpeg"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/ytdlp"
+)
+
+// albumExtractors have posts of several items,
+// the playlists of the others are too big to repost
+var albumExtractors = []string{
+	"instagram",
+	"tiktok",
+}
+
+var supportedHostnames = []string{
+	"instagram.com",
+	"www.instagram.com",
+	"tiktok.com",
+	"www.youtube.com",
+	"youtube.com",
+	"youtu.be",
+}
+
+// NewYtDlpExtractor downloads videos of the supported social networks with yt-dlp,
+// thumbnails are processed within `tempDir`
+func NewYtDlpExtractor(l *slog.Logger, yd *ytdlp.YtDlp, tempDir string) Extractor {
+	return &ytdlpExtractor{
+		l:       l,
+		yd:      yd,
+		tempDir: tempDir,
+	}
+}
+
+type ytdlpExtractor struct {
+	l       *slog.Logger
+	yd      *ytdlp.YtDlp
+	tempDir string
+}
+
+func (e *ytdlpExtractor) Name() string {
+	return "ytdlp"
+}
+
+// Cacheable reports the posts get a key
+func (e *ytdlpExtractor) Cacheable() bool {
+	return true
+}
+
+func (e *ytdlpExtractor) Match(urlString string) bool {
+	u, err := url.Parse(urlString)
+	return err == nil && slices.Contains(supportedHostnames, u.Hostname())
+}
+
+func (e *ytdlpExtractor) Resolve(ctx context.Context, urlString string) (*Post, error) {
+	r, err := e.yd.GetFormat(ctx, urlString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get format: %w", err)
+	}
+
+	if r.MediaType == "livestream" {
+		return nil, fmt.Errorf("live stream is not supported yet")
+	}
+
+	base, _, _ := strings.Cut(strings.ToLower(r.Extractor), ":")
+	if len(r.Entries) > 0 && !slices.Contains(albumExtractors, base) {
+		return nil, fmt.Errorf("%s playlists are not supported: %w", r.Extractor, ErrURLNotSupported)
+	}
+
+	// the post is cached as a whole, so a link to its first item doesn't get the album
+	id := r.ID
+	entries := r.Entries
+	if len(entries) == 0 {
+		entries = []*ytdlp.Response{r}
+	} else if r.PlaylistID != "" {
+		id = "playlist." + r.PlaylistID
+	} else {
+		id = "album." + r.ID
+	}
+
+	p := &Post{
+		URL:         r.OriginalUrl,
+		Emoji:       "🎞",
+		Title:       r.Title,
+		Description: r.Description,
+		Key:         fmt.Sprintf("%s.id.%s", strings.ToLower(r.Extractor), id),
+	}
+	for _, entry := range entries {
+		p.Items = append(p.Items, &Item{
+			Kind:     MediaVideo,
+			Download: e.download(entry),
+			Size:     entry.Filesize,
+			Duration: int(entry.Duration),
+			Width:    entry.Width,
+			Height:   entry.Height,
+		})
+	}
+	return p, nil
+}
+
+func (e *ytdlpExtractor) download(r *ytdlp.Response) func(context.Context) (*LocalMedia, error) {
+	return func(ctx context.Context) (*LocalMedia, error) {
+		video, err := e.yd.DownloadFormat(ctx, r.FormatID, r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download file: %w", err)
+		}
+
+		media := &LocalMedia{
+			File: telegram.InputFileLocal{
+				Name:   video.Name,
+				Reader: video.File,
+			},
+			Thumbnail: telegram.InputFileLocal{
+				Name:   video.Thumb.Name,
+				Reader: video.Thumb.File,
+			},
+			Dispose: video.Dispose,
+		}
+
+		if r.MediaType == "short" {
+			cropped, err := e.croppedThumb(ctx, r, video)
+			if err != nil {
+				e.l.Error("failed to crop thumbnail", "error", err)
+				return media, nil
+			}
+			media.Thumbnail = telegram.InputFileLocal{
+				Name:   video.Thumb.Name,
+				Reader: cropped.File,
+			}
+			media.Dispose = func() {
+				cropped.Dispose()
+				video.Dispose()
+			}
+		}
+
+		return media, nil
+	}
+}
+
+func (e *ytdlpExtractor) croppedThumb(ctx context.Context, r *ytdlp.Response, v *ytdlp.LocalVideo) (*ytdlp.LocalFile, error) {
+	info, err := ffmpeg.GetInfo(ctx, v.Thumb.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(info.Streams) < 1 {
+		return nil, fmt.Errorf("no stream found at %s", v.Thumb.Path)
+	}
+
+	w := r.Width * info.Streams[0].Height / r.Height
+	cropped, err := ffmpeg.Crop(ctx, e.tempDir, v.Thumb.Path, w, info.Streams[0].Height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to crop %s: %w", v.Thumb.Path, err)
+	}
+
+	f, err := os.Open(cropped)
+	if err != nil {
+		_ = os.RemoveAll(filepath.Dir(cropped))
+		return nil, fmt.Errorf("failed to open cropped file: %w", err)
+	}
+
+	// the whole temporary directory goes away on dispose
+	return &ytdlp.LocalFile{
+		File: f,
+		Path: filepath.Dir(cropped),
+	}, nil
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ailinykh/reposter/v3/internal/router"
 	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/format"
 )
 
 func New() *Info {
@@ -14,23 +16,25 @@ func New() *Info {
 
 type Info struct{}
 
-func (i *Info) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
-	m := u.Message
-	if m == nil || len(m.Commands()) == 0 || m.Commands()[0] != "/info" {
-		return nil
-	}
+func (i *Info) Register(r *router.Router) {
+	r.Command("info", router.Message(i.info), router.Menu(telegram.BotCommandScopeDefault, map[string]string{
+		"":   "Chat and sender details",
+		"ru": "Информация о чате и отправителе",
+	}))
+}
 
+func (i *Info) info(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
 	info := []string{
 		"💬 Chat",
 		fmt.Sprintf("ID: <b>%d</b>", m.Chat.ID),
-		fmt.Sprintf("Title: <b>%s</b>", m.Chat.Title),
-		fmt.Sprintf("Type: <b>%s</b>", m.Chat.Type),
+		fmt.Sprintf("Title: <b>%s</b>", format.EscapeHTML(m.Chat.Title)),
+		fmt.Sprintf("Type: <b>%s</b>", format.EscapeHTML(m.Chat.Type)),
 		"",
 		"👤 Sender",
 		fmt.Sprintf("ID: <b>%d</b>", m.From.ID),
-		fmt.Sprintf("First: <b>%s</b>", m.From.FirstName),
-		fmt.Sprintf("Last: <b>%s</b>", m.From.LastName),
-		fmt.Sprintf("Username: <b>%s</b>", m.From.Username),
+		fmt.Sprintf("First: <b>%s</b>", format.EscapeHTML(m.From.FirstName)),
+		fmt.Sprintf("Last: <b>%s</b>", format.EscapeHTML(m.From.LastName)),
+		fmt.Sprintf("Username: <b>%s</b>", format.EscapeHTML(m.From.Username)),
 		"",
 	}
 
@@ -39,8 +43,8 @@ func (i *Info) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot
 			info = append(info,
 				"💬 forward from chat",
 				fmt.Sprintf("ID: <b>%d</b>", m.ReplyToMessage.ForwardFromChat.ID),
-				fmt.Sprintf("Title: <b>%s</b>", m.ReplyToMessage.ForwardFromChat.Title),
-				fmt.Sprintf("Type: <b>%s</b>", m.ReplyToMessage.ForwardFromChat.Type),
+				fmt.Sprintf("Title: <b>%s</b>", format.EscapeHTML(m.ReplyToMessage.ForwardFromChat.Title)),
+				fmt.Sprintf("Type: <b>%s</b>", format.EscapeHTML(m.ReplyToMessage.ForwardFromChat.Type)),
 				"",
 			)
 		}
@@ -48,10 +52,10 @@ func (i *Info) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot
 			info = append(info,
 				"👤 forward from",
 				fmt.Sprintf("ID: <b>%d</b>", m.ReplyToMessage.ForwardFrom.ID),
-				fmt.Sprintf("First: <b>%s</b>", m.ReplyToMessage.ForwardFrom.FirstName),
-				fmt.Sprintf("Last: <b>%s</b>", m.ReplyToMessage.ForwardFrom.LastName),
-				fmt.Sprintf("Username: <b>%s</b>", m.ReplyToMessage.ForwardFrom.Username),
-				fmt.Sprintf("SenderName: <b>%s</b>", m.ReplyToMessage.ForwardSenderName),
+				fmt.Sprintf("First: <b>%s</b>", format.EscapeHTML(m.ReplyToMessage.ForwardFrom.FirstName)),
+				fmt.Sprintf("Last: <b>%s</b>", format.EscapeHTML(m.ReplyToMessage.ForwardFrom.LastName)),
+				fmt.Sprintf("Username: <b>%s</b>", format.EscapeHTML(m.ReplyToMessage.ForwardFrom.Username)),
+				fmt.Sprintf("SenderName: <b>%s</b>", format.EscapeHTML(m.ReplyToMessage.ForwardSenderName)),
 				"",
 			)
 		}
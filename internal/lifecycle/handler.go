@@ -0,0 +1,117 @@
+package lifecycle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/internal/router"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+// New tracks the bot membership in chats
+func New(l *slog.Logger, db *sql.DB) *Handler {
+	return &Handler{
+		l:  l,
+		db: db,
+	}
+}
+
+type Handler struct {
+	l  *slog.Logger
+	db *sql.DB
+}
+
+func (h *Handler) Register(r *router.Router) {
+	r.On(router.KindMyChatMember, nil, func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+		return h.handleMyChatMember(ctx, u.MyChatMember)
+	}, router.Name("lifecycle"))
+	r.On(router.KindMessage, func(u *telegram.Update) bool {
+		return u.Message.MigrateToChatID != 0 || u.Message.MigrateFromChatID != 0
+	}, router.Message(h.handleMigration), router.Name("lifecycle_migration"))
+}
+
+func (h *Handler) handleMyChatMember(ctx context.Context, m *telegram.ChatMemberUpdated) error {
+	switch {
+	case !m.OldChatMember.Present() && m.NewChatMember.Present():
+		h.l.Info("bot added to chat", "chat_id", m.Chat.ID, "title", m.Chat.Title, "type", m.Chat.Type, "by", m.From.ID)
+		return h.restore(ctx, m.Chat.ID)
+	case m.OldChatMember.Present() && !m.NewChatMember.Present():
+		h.l.Info("bot removed from chat", "chat_id", m.Chat.ID, "title", m.Chat.Title, "type", m.Chat.Type, "by", m.From.ID)
+		return h.cleanup(ctx, m.Chat.ID)
+	default:
+		h.l.Info("bot status changed", "chat_id", m.Chat.ID, "old", m.OldChatMember.Status, "new", m.NewChatMember.Status)
+	}
+	return nil
+}
+
+// handleMigration moves the chat data when a group is upgraded to a supergroup.
+// Both the old and the new chat get a service message, whichever comes first does the job.
+func (h *Handler) handleMigration(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
+	oldChatID, newChatID := m.Chat.ID, m.MigrateToChatID
+	if m.MigrateFromChatID != 0 {
+		oldChatID, newChatID = m.MigrateFromChatID, m.Chat.ID
+	}
+
+	h.l.Info("chat migrated to supergroup", "old_chat_id", oldChatID, "new_chat_id", newChatID)
+	return h.migrate(ctx, oldChatID, newChatID)
+}
+
+// migrate moves all the data of the chat to the new chat ID, the rows
+// already present in the new chat win over the old ones
+func (h *Handler) migrate(ctx context.Context, oldChatID, newChatID int64) error {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	repo := repository.New(h.db).WithTx(tx)
+	if err = repo.MoveChatPlayers(ctx, repository.MoveChatPlayersParams{NewChatID: newChatID, OldChatID: oldChatID}); err != nil {
+		return fmt.Errorf("failed to move players: %w", err)
+	}
+	if err = repo.MoveChatRounds(ctx, repository.MoveChatRoundsParams{NewChatID: newChatID, OldChatID: oldChatID}); err != nil {
+		return fmt.Errorf("failed to move rounds: %w", err)
+	}
+	if err = repo.MoveChatSettings(ctx, repository.MoveChatSettingsParams{NewChatID: newChatID, OldChatID: oldChatID}); err != nil {
+		return fmt.Errorf("failed to move settings: %w", err)
+	}
+	// whatever is left conflicts with the new chat
+	if err = repo.DeleteChatPlayers(ctx, oldChatID); err != nil {
+		return fmt.Errorf("failed to delete players: %w", err)
+	}
+	if err = repo.DeleteChatRounds(ctx, oldChatID); err != nil {
+		return fmt.Errorf("failed to delete rounds: %w", err)
+	}
+	if err = repo.DeleteChatSettings(ctx, oldChatID); err != nil {
+		return fmt.Errorf("failed to delete settings: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	h.l.Info("chat data moved", "old_chat_id", oldChatID, "new_chat_id", newChatID)
+	return nil
+}
+
+// cleanup marks the players of the chat the bot has left as removed.
+// Rounds and settings are kept, the bot might be added back later.
+func (h *Handler) cleanup(ctx context.Context, chatID int64) error {
+	if err := repository.New(h.db).RemoveChatPlayers(ctx, chatID); err != nil {
+		return fmt.Errorf("failed to remove players: %w", err)
+	}
+
+	h.l.Info("chat players removed", "chat_id", chatID)
+	return nil
+}
+
+// restore brings back the players of the chat the bot has been added to again
+func (h *Handler) restore(ctx context.Context, chatID int64) error {
+	if err := repository.New(h.db).RestoreChatPlayers(ctx, chatID); err != nil {
+		return fmt.Errorf("failed to restore players: %w", err)
+	}
+	return nil
+}
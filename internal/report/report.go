@@ -0,0 +1,200 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/internal/router"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/format"
+)
+
+// New sends summaries of failed updates to the admin chat, nothing is sent when chatID is zero
+func New(l *slog.Logger, chatID int64, opts ...func(*Reporter)) *Reporter {
+	r := &Reporter{
+		l:        l,
+		chatID:   chatID,
+		window:   time.Hour,
+		limit:    10,
+		interval: time.Hour,
+		seen:     map[string]*incident{},
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// WithDedupWindow sets how long the same error of the same route is not reported again
+func WithDedupWindow(window time.Duration) func(*Reporter) {
+	return func(r *Reporter) {
+		r.window = window
+	}
+}
+
+// WithRateLimit sets how many reports could be sent within the interval
+func WithRateLimit(limit int, interval time.Duration) func(*Reporter) {
+	return func(r *Reporter) {
+		r.limit = limit
+		r.interval = interval
+	}
+}
+
+type Reporter struct {
+	l        *slog.Logger
+	chatID   int64
+	window   time.Duration
+	limit    int
+	interval time.Duration
+
+	mu         sync.Mutex
+	seen       map[string]*incident
+	sent       []time.Time
+	suppressed int
+}
+
+type incident struct {
+	reported time.Time
+	repeated int
+}
+
+// Middleware reports the errors returned by the routes,
+// it should go before router.Recovery to get the panics as well
+func (r *Reporter) Middleware() router.Middleware {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+			err := next(ctx, u, bot)
+			if err != nil {
+				r.Report(bot, router.RouteName(ctx), u, err)
+			}
+			return err
+		}
+	}
+}
+
+type Handler interface {
+	Handle(context.Context, *telegram.Update, *telegram.Bot) error
+}
+
+// Recover wraps the update handler, so a panic outside of the routes doesn't kill the process
+func (r *Reporter) Recover(h Handler) Handler {
+	return &recoverer{r: r, h: h}
+}
+
+type recoverer struct {
+	r *Reporter
+	h Handler
+}
+
+func (rc *recoverer) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			rc.r.l.Error("🔥 panic recovered", "update_id", u.ID, "panic", v)
+			err = &router.PanicError{Value: v, Stack: debug.Stack()}
+			rc.r.Report(bot, "", u, err)
+		}
+	}()
+	return rc.h.Handle(ctx, u, bot)
+}
+
+// Report sends the summary of the error unless the same one has been reported recently
+// or too many reports have been sent already
+func (r *Reporter) Report(bot *telegram.Bot, route string, u *telegram.Update, err error) {
+	if r.chatID == 0 {
+		return
+	}
+
+	repeated, suppressed, ok := r.allow(route+": "+err.Error(), time.Now())
+	if !ok {
+		return
+	}
+
+	nodes := []format.Node{
+		format.Bold(format.Textf("🔥 %s failed", routeOrUnknown(route))),
+		format.Text("\n"),
+		format.Textf("chat: %d, update: %d", router.ChatID(u), u.ID),
+	}
+	if repeated > 0 {
+		nodes = append(nodes, format.Textf("\nrepeated %d times since the last report", repeated))
+	}
+	if suppressed > 0 {
+		nodes = append(nodes, format.Textf("\n%d reports suppressed by the rate limit", suppressed))
+	}
+	nodes = append(nodes, format.Text("\n\n"), format.Pre(chain(err), ""))
+	var p *router.PanicError
+	if errors.As(err, &p) {
+		nodes = append(nodes, format.Text("\n"), format.Pre(string(p.Stack), ""))
+	}
+
+	// the update context could be cancelled already and it's bound to the update topic
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := bot.SendMessage(ctx, &telegram.SendMessageParams{
+		ChatID:    r.chatID,
+		Text:      format.Render(telegram.ParseModeHTML, format.MessageLimit, nodes...),
+		ParseMode: telegram.ParseModeHTML,
+	}); err != nil {
+		r.l.Error("failed to send error report", "chat_id", r.chatID, "error", err)
+	}
+}
+
+// allow decides whether the error is worth reporting and returns how many times
+// it has been repeated and how many reports have been dropped since the last time
+func (r *Reporter) allow(key string, now time.Time) (repeated int, suppressed int, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, i := range r.seen {
+		if now.Sub(i.reported) >= r.window && i.repeated == 0 {
+			delete(r.seen, k)
+		}
+	}
+
+	i, found := r.seen[key]
+	if found && now.Sub(i.reported) < r.window {
+		i.repeated++
+		return 0, 0, false
+	}
+
+	for len(r.sent) > 0 && now.Sub(r.sent[0]) >= r.interval {
+		r.sent = r.sent[1:]
+	}
+	if len(r.sent) >= r.limit {
+		r.suppressed++
+		if found {
+			i.repeated++
+		}
+		return 0, 0, false
+	}
+	r.sent = append(r.sent, now)
+
+	if found {
+		repeated = i.repeated
+	}
+	r.seen[key] = &incident{reported: now}
+	suppressed, r.suppressed = r.suppressed, 0
+	return repeated, suppressed, true
+}
+
+// chain lists the wrapped errors from the outermost one
+func chain(err error) string {
+	var lines []string
+	for ; err != nil; err = errors.Unwrap(err) {
+		lines = append(lines, fmt.Sprintf("%T: %s", err, err))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func routeOrUnknown(route string) string {
+	if route == "" {
+		return "update handling"
+	}
+	return route
+}
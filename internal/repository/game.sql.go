@@ -80,6 +80,26 @@ func (q *Queries) CreateRound(ctx context.Context, arg CreateRoundParams) (GameR
 	return i, err
 }
 
+const deleteChatPlayers = `-- name: DeleteChatPlayers :exec
+DELETE FROM game_players WHERE
+  chat_id = $1
+`
+
+func (q *Queries) DeleteChatPlayers(ctx context.Context, chatID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteChatPlayers, chatID)
+	return err
+}
+
+const deleteChatRounds = `-- name: DeleteChatRounds :exec
+DELETE FROM game_rounds WHERE
+  chat_id = $1
+`
+
+func (q *Queries) DeleteChatRounds(ctx context.Context, chatID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteChatRounds, chatID)
+	return err
+}
+
 const getPlayers = `-- name: GetPlayers :many
 SELECT id, chat_id, user_id, first_name, last_name, username, created_at, updated_at, removed_at FROM game_players WHERE
   chat_id = $1
@@ -171,6 +191,72 @@ func (q *Queries) GetRounds(ctx context.Context, chatID int64) ([]GetRoundsRow,
 	return items, nil
 }
 
+const moveChatPlayers = `-- name: MoveChatPlayers :exec
+UPDATE game_players SET
+  chat_id = $1, updated_at = NOW()
+WHERE
+  chat_id = $2
+  AND NOT EXISTS (
+    SELECT 1 FROM game_players p
+    WHERE p.chat_id = $1 AND p.user_id = game_players.user_id
+  )
+`
+
+type MoveChatPlayersParams struct {
+	NewChatID int64
+	OldChatID int64
+}
+
+func (q *Queries) MoveChatPlayers(ctx context.Context, arg MoveChatPlayersParams) error {
+	_, err := q.db.ExecContext(ctx, moveChatPlayers, arg.NewChatID, arg.OldChatID)
+	return err
+}
+
+const moveChatRounds = `-- name: MoveChatRounds :exec
+UPDATE game_rounds SET
+  chat_id = $1, updated_at = NOW()
+WHERE
+  chat_id = $2
+  AND NOT EXISTS (
+    SELECT 1 FROM game_rounds r
+    WHERE r.chat_id = $1 AND r.user_id = game_rounds.user_id
+  )
+`
+
+type MoveChatRoundsParams struct {
+	NewChatID int64
+	OldChatID int64
+}
+
+func (q *Queries) MoveChatRounds(ctx context.Context, arg MoveChatRoundsParams) error {
+	_, err := q.db.ExecContext(ctx, moveChatRounds, arg.NewChatID, arg.OldChatID)
+	return err
+}
+
+const removeChatPlayers = `-- name: RemoveChatPlayers :exec
+UPDATE game_players SET
+  removed_at = NOW()
+WHERE
+  chat_id = $1 AND removed_at IS NULL
+`
+
+func (q *Queries) RemoveChatPlayers(ctx context.Context, chatID int64) error {
+	_, err := q.db.ExecContext(ctx, removeChatPlayers, chatID)
+	return err
+}
+
+const restoreChatPlayers = `-- name: RestoreChatPlayers :exec
+UPDATE game_players SET
+  removed_at = NULL
+WHERE
+  chat_id = $1
+`
+
+func (q *Queries) RestoreChatPlayers(ctx context.Context, chatID int64) error {
+	_, err := q.db.ExecContext(ctx, restoreChatPlayers, chatID)
+	return err
+}
+
 const updatePlayer = `-- name: UpdatePlayer :many
 UPDATE game_players SET
   first_name=$2, last_name=$3, username=$4, updated_at=NOW()
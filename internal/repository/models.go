@@ -49,3 +49,18 @@ type GameRound struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+type ProcessedUpdate struct {
+	ID        uuid.UUID
+	BotID     int64
+	UpdateID  int64
+	CreatedAt time.Time
+}
+
+type UpdateOffset struct {
+	ID           uuid.UUID
+	BotID        int64
+	NextUpdateID int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
@@ -10,6 +10,16 @@ import (
 	"encoding/json"
 )
 
+const deleteChatSettings = `-- name: DeleteChatSettings :exec
+DELETE FROM chat_settings WHERE
+  chat_id = $1
+`
+
+func (q *Queries) DeleteChatSettings(ctx context.Context, chatID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteChatSettings, chatID)
+	return err
+}
+
 const getSettings = `-- name: GetSettings :one
 SELECT id, chat_id, key, value, created_at, updated_at FROM chat_settings WHERE
   chat_id = $1 AND key = $2
@@ -34,6 +44,27 @@ func (q *Queries) GetSettings(ctx context.Context, arg GetSettingsParams) (ChatS
 	return i, err
 }
 
+const moveChatSettings = `-- name: MoveChatSettings :exec
+UPDATE chat_settings SET
+  chat_id = $1, updated_at = NOW()
+WHERE
+  chat_id = $2
+  AND NOT EXISTS (
+    SELECT 1 FROM chat_settings s
+    WHERE s.chat_id = $1 AND s.key = chat_settings.key
+  )
+`
+
+type MoveChatSettingsParams struct {
+	NewChatID int64
+	OldChatID int64
+}
+
+func (q *Queries) MoveChatSettings(ctx context.Context, arg MoveChatSettingsParams) error {
+	_, err := q.db.ExecContext(ctx, moveChatSettings, arg.NewChatID, arg.OldChatID)
+	return err
+}
+
 const setSettings = `-- name: SetSettings :one
 INSERT INTO chat_settings
   (chat_id, key, value)
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"context"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+// Kind matches the update field name https://core.telegram.org/bots/api#update
+type Kind string
+
+const (
+	KindUnknown            Kind = ""
+	KindMessage            Kind = "message"
+	KindInlineQuery        Kind = "inline_query"
+	KindChosenInlineResult Kind = "chosen_inline_result"
+	KindCallbackQuery      Kind = "callback_query"
+	KindEditedMessage      Kind = "edited_message"
+	KindChannelPost        Kind = "channel_post"
+	KindEditedChannelPost  Kind = "edited_channel_post"
+	KindMyChatMember       Kind = "my_chat_member"
+	KindChatMember         Kind = "chat_member"
+	KindMessageReaction    Kind = "message_reaction"
+)
+
+func KindOf(u *telegram.Update) Kind {
+	switch {
+	case u.Message != nil:
+		return KindMessage
+	case u.InlineQuery != nil:
+		return KindInlineQuery
+	case u.ChosenInlineResult != nil:
+		return KindChosenInlineResult
+	case u.CallbackQuery != nil:
+		return KindCallbackQuery
+	case u.EditedMessage != nil:
+		return KindEditedMessage
+	case u.ChannelPost != nil:
+		return KindChannelPost
+	case u.EditedChannelPost != nil:
+		return KindEditedChannelPost
+	case u.MyChatMember != nil:
+		return KindMyChatMember
+	case u.ChatMember != nil:
+		return KindChatMember
+	case u.MessageReaction != nil:
+		return KindMessageReaction
+	default:
+		return KindUnknown
+	}
+}
+
+// origin returns the message the update is about, if any
+func origin(u *telegram.Update) *telegram.Message {
+	switch {
+	case u.Message != nil:
+		return u.Message
+	case u.EditedMessage != nil:
+		return u.EditedMessage
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.MaybeInaccessibleMessage
+	default:
+		return nil
+	}
+}
+
+// ChatID returns the chat the update comes from,
+// or the user for the updates without a chat, e.g. inline queries
+func ChatID(u *telegram.Update) int64 {
+	switch {
+	case u.Message != nil:
+		return u.Message.Chat.ID
+	case u.CallbackQuery != nil && u.CallbackQuery.MaybeInaccessibleMessage != nil:
+		return u.CallbackQuery.MaybeInaccessibleMessage.Chat.ID
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.From.ID
+	case u.InlineQuery != nil:
+		return u.InlineQuery.From.ID
+	case u.ChosenInlineResult != nil:
+		return u.ChosenInlineResult.From.ID
+	case u.EditedMessage != nil:
+		return u.EditedMessage.Chat.ID
+	case u.ChannelPost != nil:
+		return u.ChannelPost.Chat.ID
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost.Chat.ID
+	case u.MyChatMember != nil:
+		return u.MyChatMember.Chat.ID
+	case u.ChatMember != nil:
+		return u.ChatMember.Chat.ID
+	case u.MessageReaction != nil:
+		return u.MessageReaction.Chat.ID
+	default:
+		return 0
+	}
+}
+
+// Message adapts a handler of the update message
+func Message(fn func(context.Context, *telegram.Message, *telegram.Bot) error) HandlerFunc {
+	return func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+		return fn(ctx, u.Message, bot)
+	}
+}
+
+// ChannelPost adapts a handler of the update channel post
+func ChannelPost(fn func(context.Context, *telegram.Message, *telegram.Bot) error) HandlerFunc {
+	return func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+		return fn(ctx, u.ChannelPost, bot)
+	}
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+// Menu publishes the command in the bot menu of chats within the scope.
+// Descriptions are keyed by language code, an empty one is the default.
+func Menu(scope string, descriptions map[string]string) RouteOption {
+	return func(r *route) {
+		r.menu = &menu{
+			scope:        scope,
+			descriptions: descriptions,
+		}
+	}
+}
+
+type menu struct {
+	scope        string
+	descriptions map[string]string
+}
+
+// standardScopes are cleaned up when there is nothing to show
+var standardScopes = []string{
+	telegram.BotCommandScopeDefault,
+	telegram.BotCommandScopeAllPrivateChats,
+	telegram.BotCommandScopeAllGroupChats,
+}
+
+// Menus builds command lists for every scope and language.
+// Telegram shows the most specific scope only, so the commands
+// of the default scope are duplicated to the other scopes.
+func (r *Router) Menus() []*telegram.SetMyCommandsParams {
+	scopes := []string{telegram.BotCommandScopeDefault}
+	languages := []string{""}
+	for _, rt := range r.menu {
+		if !slices.Contains(scopes, rt.menu.scope) {
+			scopes = append(scopes, rt.menu.scope)
+		}
+		for lang := range rt.menu.descriptions {
+			if !slices.Contains(languages, lang) {
+				languages = append(languages, lang)
+			}
+		}
+	}
+	slices.Sort(languages)
+
+	var menus []*telegram.SetMyCommandsParams
+	for _, scope := range scopes {
+		for _, lang := range languages {
+			params := &telegram.SetMyCommandsParams{
+				Scope:        &telegram.BotCommandScope{Type: scope},
+				LanguageCode: lang,
+			}
+			for _, rt := range r.menu {
+				if rt.menu.scope != scope && rt.menu.scope != telegram.BotCommandScopeDefault {
+					continue
+				}
+
+				description, ok := rt.menu.descriptions[lang]
+				if !ok {
+					description = rt.menu.descriptions[""]
+				}
+				params.Commands = append(params.Commands, telegram.BotCommand{
+					Command:     rt.command(),
+					Description: description,
+				})
+			}
+			if len(params.Commands) > 0 {
+				menus = append(menus, params)
+			}
+		}
+	}
+	return menus
+}
+
+// PublishCommands replaces the bot menu with the commands registered in the router
+// https://core.telegram.org/bots/features#commands
+func (r *Router) PublishCommands(ctx context.Context, bot *telegram.Bot) error {
+	menus := r.Menus()
+	for _, params := range menus {
+		if err := bot.SetMyCommands(ctx, params); err != nil {
+			return fmt.Errorf("failed to set commands for scope %s and language %q: %w", params.Scope.Type, params.LanguageCode, err)
+		}
+	}
+
+	// localized menus are separate, so they are cleaned up for every language in use
+	languages := []string{""}
+	for _, params := range menus {
+		if !slices.Contains(languages, params.LanguageCode) {
+			languages = append(languages, params.LanguageCode)
+		}
+	}
+
+	for _, scope := range standardScopes {
+		for _, lang := range languages {
+			used := slices.ContainsFunc(menus, func(params *telegram.SetMyCommandsParams) bool {
+				return params.Scope.Type == scope && params.LanguageCode == lang
+			})
+			if used {
+				continue
+			}
+			if err := bot.DeleteMyCommands(ctx, &telegram.DeleteMyCommandsParams{
+				Scope:        &telegram.BotCommandScope{Type: scope},
+				LanguageCode: lang,
+			}); err != nil {
+				return fmt.Errorf("failed to delete commands for scope %s and language %q: %w", scope, lang, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rt *route) command() string {
+	if rt.prefix != "" {
+		return rt.prefix
+	}
+	return strings.TrimPrefix(rt.name, "/")
+}
@@ -0,0 +1,58 @@
+package router_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ailinykh/reposter/v3/internal/router"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/telegramtest"
+)
+
+func TestPublishCommands(t *testing.T) {
+	server := telegramtest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Reset()
+
+	r := router.New()
+	r.Command("help", func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+		return nil
+	}, router.Menu(telegram.BotCommandScopeAllPrivateChats, map[string]string{
+		"":   "Help",
+		"ru": "Помощь",
+	}))
+
+	if err := r.PublishCommands(t.Context(), bot); err != nil {
+		t.Fatal(err)
+	}
+
+	var set, deleted []string
+	for _, c := range server.Calls("setMyCommands") {
+		set = append(set, menuName(c))
+	}
+	for _, c := range server.Calls("deleteMyCommands") {
+		deleted = append(deleted, menuName(c))
+	}
+	slices.Sort(set)
+	slices.Sort(deleted)
+
+	if want := []string{"all_private_chats/", "all_private_chats/ru"}; !slices.Equal(set, want) {
+		t.Errorf("set menus = %q, want %q", set, want)
+	}
+	// stale localized menus are removed as well
+	if want := []string{"all_group_chats/", "all_group_chats/ru", "default/", "default/ru"}; !slices.Equal(deleted, want) {
+		t.Errorf("deleted menus = %q, want %q", deleted, want)
+	}
+}
+
+func menuName(c telegramtest.Call) string {
+	var scope telegram.BotCommandScope
+	_ = c.Decode("scope", &scope)
+	return scope.Type + "/" + c.String("language_code")
+}
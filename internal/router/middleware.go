@@ -0,0 +1,67 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+func Logging(l *slog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+			start := time.Now()
+			err := next(ctx, u, bot)
+			if err != nil {
+				l.Error("❌ failed to process update", "route", RouteName(ctx), "update_id", u.ID, "duration", time.Since(start), "error", err)
+			} else {
+				l.Debug("update processed", "route", RouteName(ctx), "update_id", u.ID, "duration", time.Since(start))
+			}
+			return err
+		}
+	}
+}
+
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Recovery turns a panic into *PanicError, so the bot keeps running
+func Recovery(l *slog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) (err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					l.Error("🔥 panic recovered", "route", RouteName(ctx), "update_id", u.ID, "panic", v)
+					err = &PanicError{Value: v, Stack: debug.Stack()}
+				}
+			}()
+			return next(ctx, u, bot)
+		}
+	}
+}
+
+// Auth silently drops updates which are not allowed
+func Auth(allow func(*telegram.Update) bool) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+			if !allow(u) {
+				return nil
+			}
+			return next(ctx, u, bot)
+		}
+	}
+}
+
+// PrivateChat allows messages in private chats only
+func PrivateChat(u *telegram.Update) bool {
+	return u.Message != nil && u.Message.Chat.Private()
+}
@@ -0,0 +1,230 @@
+package router
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+type HandlerFunc func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error
+
+type MatchFunc func(u *telegram.Update) bool
+
+type Middleware func(next HandlerFunc) HandlerFunc
+
+type RouteOption func(*route)
+
+// Name overrides the route name used by middlewares
+func Name(name string) RouteOption {
+	return func(r *route) {
+		r.name = name
+	}
+}
+
+// With applies middlewares to a single route
+func With(middlewares ...Middleware) RouteOption {
+	return func(r *route) {
+		r.middlewares = append(r.middlewares, middlewares...)
+	}
+}
+
+// Timeout overrides the default route timeout
+func Timeout(timeout time.Duration) RouteOption {
+	return func(r *route) {
+		r.timeout = timeout
+	}
+}
+
+// WithTimeout limits the time a route could spend on an update
+func WithTimeout(timeout time.Duration) func(*Router) {
+	return func(r *Router) {
+		r.timeout = timeout
+	}
+}
+
+func New(opts ...func(*Router)) *Router {
+	r := &Router{
+		commands: map[string]*route{},
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Router delivers an update to the first matching route only.
+// Commands addressed to the bot take precedence over the update kind routes.
+type Router struct {
+	commands    map[string]*route
+	prefixes    []*route
+	routes      []*route
+	middlewares []Middleware
+	timeout     time.Duration
+	menu        []*route
+}
+
+type route struct {
+	name        string
+	prefix      string
+	kind        Kind
+	match       MatchFunc
+	handler     HandlerFunc
+	middlewares []Middleware
+	timeout     time.Duration
+	menu        *menu
+}
+
+// Use applies middlewares to every route
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Command registers a handler for a `/name` or `/name@botusername` command
+func (r *Router) Command(name string, h HandlerFunc, opts ...RouteOption) {
+	rt := newRoute("/"+name, KindMessage, nil, h, opts)
+	r.commands[name] = rt
+	r.addMenu(rt)
+}
+
+// CommandPrefix registers a handler for any `/prefix*` command without an exact match
+func (r *Router) CommandPrefix(prefix string, h HandlerFunc, opts ...RouteOption) {
+	rt := newRoute("/"+prefix+"*", KindMessage, nil, h, opts)
+	rt.prefix = prefix
+	r.prefixes = append(r.prefixes, rt)
+	sort.SliceStable(r.prefixes, func(i, j int) bool {
+		return len(r.prefixes[i].prefix) > len(r.prefixes[j].prefix)
+	})
+	r.addMenu(rt)
+}
+
+// On registers a handler for updates of the kind, `match` could be nil to accept any of them
+func (r *Router) On(kind Kind, match MatchFunc, h HandlerFunc, opts ...RouteOption) {
+	r.routes = append(r.routes, newRoute(string(kind), kind, match, h, opts))
+}
+
+// Handle is a shortcut for Dispatch when it doesn't matter if the update has been handled
+func (r *Router) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+	_, err := r.Dispatch(ctx, u, bot)
+	return err
+}
+
+// Dispatch passes the update to the matching route and reports whether there was one
+func (r *Router) Dispatch(ctx context.Context, u *telegram.Update, bot *telegram.Bot) (bool, error) {
+	rt := r.find(u, bot.Username)
+	if rt == nil {
+		return false, nil
+	}
+
+	h := rt.handler
+	for i := len(rt.middlewares) - 1; i >= 0; i-- {
+		h = rt.middlewares[i](h)
+	}
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+
+	timeout := r.timeout
+	if rt.timeout > 0 {
+		timeout = rt.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// replies stay in the forum topic the update came from
+	ctx = telegram.WithTopic(ctx, origin(u))
+	return true, h(context.WithValue(ctx, routeKey{}, rt.name), u, bot)
+}
+
+// AllowedUpdates lists the update kinds the routes are registered for,
+// so Telegram doesn't send anything else
+func (r *Router) AllowedUpdates() []string {
+	var kinds []string
+	if len(r.commands) > 0 || len(r.prefixes) > 0 {
+		kinds = append(kinds, string(KindMessage))
+	}
+	for _, rt := range r.routes {
+		if !slices.Contains(kinds, string(rt.kind)) {
+			kinds = append(kinds, string(rt.kind))
+		}
+	}
+	return kinds
+}
+
+func (r *Router) addMenu(rt *route) {
+	if rt.menu != nil {
+		r.menu = append(r.menu, rt)
+	}
+}
+
+func (r *Router) find(u *telegram.Update, username string) *route {
+	kind := KindOf(u)
+
+	// a command never falls through to the other routes,
+	// even if it is addressed to another bot or not registered
+	if name, ok := command(u.Message); ok {
+		bot, mention, found := strings.Cut(name, "@")
+		if found && !strings.EqualFold(mention, username) {
+			return nil
+		}
+		if rt, ok := r.commands[bot]; ok {
+			return rt
+		}
+		for _, rt := range r.prefixes {
+			if strings.HasPrefix(bot, rt.prefix) {
+				return rt
+			}
+		}
+		return nil
+	}
+
+	for _, rt := range r.routes {
+		if rt.kind == kind && (rt.match == nil || rt.match(u)) {
+			return rt
+		}
+	}
+
+	return nil
+}
+
+// command returns the command the message starts with,
+// including the bot username it is addressed to, if any
+func command(m *telegram.Message) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+
+	commands := m.Commands()
+	if len(commands) == 0 || !strings.HasPrefix(m.Text, commands[0]) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(commands[0], "/"), true
+}
+
+func newRoute(name string, kind Kind, match MatchFunc, h HandlerFunc, opts []RouteOption) *route {
+	rt := &route{
+		name:    name,
+		kind:    kind,
+		match:   match,
+		handler: h,
+	}
+	for _, o := range opts {
+		o(rt)
+	}
+	return rt
+}
+
+type routeKey struct{}
+
+// RouteName returns the name of the route handling the update
+func RouteName(ctx context.Context) string {
+	name, _ := ctx.Value(routeKey{}).(string)
+	return name
+}
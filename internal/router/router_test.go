@@ -0,0 +1,89 @@
+package router_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ailinykh/reposter/v3/internal/info"
+	"github.com/ailinykh/reposter/v3/internal/router"
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/telegramtest"
+)
+
+func TestCommands(t *testing.T) {
+	server := telegramtest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var links int
+	r := router.New()
+	info.New().Register(r)
+	r.On(router.KindMessage, func(u *telegram.Update) bool {
+		return len(u.Message.URLs()) > 0
+	}, func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+		links++
+		return nil
+	})
+
+	tests := []struct {
+		name    string
+		text    string
+		handled bool
+		info    int
+		links   int
+	}{
+		{"command", "/info", true, 1, 0},
+		{"command of the bot", "/info@test_bot https://example.com", true, 1, 0},
+		{"command of another bot", "/info@other_bot https://example.com", false, 0, 0},
+		{"unknown command", "/unknown https://example.com", false, 0, 0},
+		{"link", "https://example.com /info", true, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.Reset()
+			links = 0
+
+			handled, err := r.Dispatch(t.Context(), &telegram.Update{ID: 1, Message: message(tt.text)}, bot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if handled != tt.handled {
+				t.Errorf("handled = %v, want %v", handled, tt.handled)
+			}
+			if calls := server.Calls("sendMessage"); len(calls) != tt.info {
+				t.Errorf("sendMessage calls = %d, want %d", len(calls), tt.info)
+			}
+			if links != tt.links {
+				t.Errorf("link route calls = %d, want %d", links, tt.links)
+			}
+		})
+	}
+}
+
+// message makes a text message with the entities Telegram would detect
+func message(text string) *telegram.Message {
+	m := &telegram.Message{
+		ID:   1,
+		Text: text,
+		Chat: &telegram.Chat{ID: 1, Type: "private"},
+		From: &telegram.User{ID: 1, FirstName: "John"},
+	}
+
+	offset := 0
+	for word := range strings.SplitSeq(text, " ") {
+		switch {
+		case strings.HasPrefix(word, "/"):
+			m.Entities = append(m.Entities, telegram.MessageEntity{Type: "bot_command", Offset: offset, Length: len(word)})
+		case strings.HasPrefix(word, "https://"):
+			m.Entities = append(m.Entities, telegram.MessageEntity{Type: "url", Offset: offset, Length: len(word)})
+		}
+		offset += len(word) + 1
+	}
+	return m
+}
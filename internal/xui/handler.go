@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ailinykh/reposter/v3/internal/repository"
+	"github.com/ailinykh/reposter/v3/internal/router"
 	"github.com/ailinykh/reposter/v3/pkg/helpers"
 	"github.com/ailinykh/reposter/v3/pkg/telegram"
 )
@@ -23,7 +24,7 @@ func NewHandler(client *Client, l *slog.Logger, repo SettingsRepository) *Handle
 		client: client,
 		l:      l,
 		repo:   repo,
-		state:  helpers.NewSafeMap[int64, string](),
+		state:  helpers.NewSafeMap[int64, prompt](),
 	}
 }
 
@@ -31,60 +32,95 @@ type Handler struct {
 	client *Client
 	l      *slog.Logger
 	repo   SettingsRepository
-	state  *helpers.SafeMap[int64, string]
+	state  *helpers.SafeMap[int64, prompt]
 }
 
-func (h *Handler) Handle(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
-	// should answer
-	if u.CallbackQuery != nil && strings.HasPrefix(u.CallbackQuery.Data, "vpn_") {
-		return h.handleCallback(ctx, u.CallbackQuery, bot)
-	}
+// promptTTL is how long a prompt waits for an answer
+const promptTTL = 15 * time.Minute
 
-	// only for private chats
-	if u.Message == nil || !u.Message.Chat.Private() {
-		return nil
-	}
+// prompt is a ForceReply message waiting for an answer in a chat
+type prompt struct {
+	messageID int64
+	expiresAt time.Time
+	answer    func(ctx context.Context, promptID int64, m *telegram.Message, bot *telegram.Bot) error
+}
 
-	// check if key name expected
-	if state, ok := h.state.Get(u.Message.Chat.ID); ok {
-		// consider to use another delimeter maybe
-		parts := strings.Split(state, "_")
-		if messageID, err := strconv.ParseInt(parts[len(parts)-1], 10, 64); err == nil {
-			state = strings.Join(parts[:len(parts)-1], "_")
-
-			switch state {
-			case "vpn_enter_new_key_name_expected":
-				return h.createKey(ctx, messageID, u.Message, bot)
-			case "vpn_enter_existing_key_name_expected":
-				return h.deleteKey(ctx, messageID, u.Message, bot)
-			}
+func (h *Handler) Register(r *router.Router) {
+	private := router.With(router.Auth(router.PrivateChat))
+	r.On(router.KindCallbackQuery, func(u *telegram.Update) bool {
+		return strings.HasPrefix(u.CallbackQuery.Data, "vpn_")
+	}, func(ctx context.Context, u *telegram.Update, bot *telegram.Bot) error {
+		return h.handleCallback(ctx, u.CallbackQuery, bot)
+	}, router.Name("xui_callback"))
+	// check if it's an answer to the key name prompt
+	r.On(router.KindMessage, func(u *telegram.Update) bool {
+		p, ok := h.state.Get(u.Message.Chat.ID)
+		return ok && u.Message.Chat.Private() && u.Message.ReplyToMessage != nil && u.Message.ReplyToMessage.ID == p.messageID
+	}, router.Message(h.handleState), router.Name("xui_state"))
+	r.Command("start", router.Message(h.handlePayload), private)
+	r.Command("cancel", router.Message(h.cancel), private)
+	r.Command("vpnhelp", router.Message(func(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
+		if h.checkAccess(m) {
+			return h.help(ctx, m, bot)
 		}
+		_, err := bot.SendMessage(ctx, &telegram.SendMessageParams{
+			ChatID: m.Chat.ID,
+			Text:   i18n("vpn_mislead"),
+		})
+		return err
+	}), private, router.Menu(telegram.BotCommandScopeAllPrivateChats, map[string]string{
+		"":   "VPN keys",
+		"ru": "VPN ключи",
+	}))
+}
 
-		h.l.Error("unexpected vpn state", "state", state)
-		h.state.Delete(u.Message.Chat.ID)
+func (h *Handler) handleState(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
+	p, _ := h.state.Get(m.Chat.ID)
+	if time.Now().After(p.expiresAt) {
+		h.state.Delete(m.Chat.ID)
 		_, err := bot.SendMessage(ctx, &telegram.SendMessageParams{
-			ChatID: u.Message.Chat.ID,
-			Text:   i18n("vpn_unexpected_state"),
+			ChatID: m.Chat.ID,
+			Text:   i18n("vpn_prompt_expired"),
 		})
 		return err
 	}
+	return p.answer(ctx, p.messageID, m, bot)
+}
 
-	// check commands
-	for _, command := range u.Message.Commands() {
-		switch command {
-		case "/start":
-			return h.handlePayload(ctx, u.Message, bot)
-		case "/vpnhelp":
-			if h.checkAccess(u.Message) {
-				return h.help(ctx, u.Message, bot)
-			}
-			_, err := bot.SendMessage(ctx, &telegram.SendMessageParams{
-				ChatID: u.Message.Chat.ID,
-				Text:   i18n("vpn_mislead"),
-			})
-			return err
-		}
+// cancel drops the pending prompt and brings the menu back
+func (h *Handler) cancel(ctx context.Context, m *telegram.Message, bot *telegram.Bot) error {
+	p, ok := h.state.Get(m.Chat.ID)
+	if !ok {
+		return nil
+	}
+
+	h.state.Delete(m.Chat.ID)
+
+	if _, err := bot.DeleteMessage(ctx, &telegram.DeleteMessageParams{
+		ChatID:    m.Chat.ID,
+		MessageID: p.messageID,
+	}); err != nil {
+		h.l.Error("failed to delete prompt", "error", err)
 	}
+	return h.help(ctx, m, bot)
+}
+
+// prompt asks for a text answer, the answer is expected as a reply to the prompt
+// within promptTTL, /cancel abandons it
+func (h *Handler) prompt(ctx context.Context, chatID int64, text, placeholder string, answer func(context.Context, int64, *telegram.Message, *telegram.Bot) error, bot *telegram.Bot) error {
+	m, err := bot.SendMessage(ctx, &telegram.SendMessageParams{
+		ChatID:    chatID,
+		Text:      text + "\n\n" + i18n("vpn_prompt_cancel"),
+		ParseMode: telegram.ParseModeHTML,
+		ReplyMarkup: telegram.ForceReply{
+			InputFieldPlaceholder: placeholder,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send prompt: %w", err)
+	}
+
+	h.state.Set(chatID, prompt{messageID: m.ID, expiresAt: time.Now().Add(promptTTL), answer: answer})
 	return nil
 }
 
@@ -157,11 +193,13 @@ func (h *Handler) handlePayload(ctx context.Context, m *telegram.Message, bot *t
 func (h *Handler) createKey(ctx context.Context, messageID int64, m *telegram.Message, bot *telegram.Bot) error {
 	h.l.Info("create new key", "name", m.Text)
 	if len(m.Text) > 64 {
-		_, err := bot.SendMessage(ctx, &telegram.SendMessageParams{
-			ChatID: m.Chat.ID,
-			Text:   i18n("vpn_enter_create_key_name_too_long"),
-		})
-		return err
+		if _, err := bot.DeleteMessage(ctx, &telegram.DeleteMessageParams{
+			ChatID:    m.Chat.ID,
+			MessageID: messageID,
+		}); err != nil {
+			h.l.Error("failed to delete prompt", "error", err)
+		}
+		return h.prompt(ctx, m.Chat.ID, i18n("vpn_enter_create_key_name_too_long"), i18n("vpn_key_name_placeholder"), h.createKey, bot)
 	}
 
 	key, err := h.client.CreateKey(ctx, m.Text, m.Chat.ID, m.From)
@@ -179,6 +217,7 @@ func (h *Handler) createKey(ctx context.Context, messageID int64, m *telegram.Me
 	}
 
 	buttons := [][]telegram.InlineKeyboardButton{
+		{{Text: i18n("vpn_button_copy_key"), CopyText: &telegram.CopyTextButton{Text: key.Key}}},
 		{{Text: i18n("vpn_button_manage_key"), CallbackData: "vpn_manage_key"}},
 	}
 	_, err = bot.SendMessage(ctx, &telegram.SendMessageParams{
@@ -250,21 +289,14 @@ func (h *Handler) handleCallback(ctx context.Context, c *telegram.CallbackQuery,
 
 	switch c.Data {
 	case "vpn_create_key":
-		h.state.Set(m.Chat.ID, fmt.Sprintf("vpn_enter_new_key_name_expected_%d", m.ID))
-
-		buttons := [][]telegram.InlineKeyboardButton{
-			{{Text: i18n("vpn_button_back"), CallbackData: "vpn_back"}},
-		}
-		_, err := bot.EditMessageText(ctx, &telegram.EditMessageTextParams{
+		// the prompt replaces the menu, a new one comes with the result
+		if _, err := bot.DeleteMessage(ctx, &telegram.DeleteMessageParams{
 			ChatID:    m.Chat.ID,
 			MessageID: m.ID,
-			Text:      i18n("vpn_enter_create_key_name"),
-			ParseMode: telegram.ParseModeHTML,
-			ReplyMarkup: telegram.InlineKeyboardMarkup{
-				InlineKeyboard: buttons,
-			},
-		})
-		return err
+		}); err != nil {
+			return fmt.Errorf("failed to delete message: %w", err)
+		}
+		return h.prompt(ctx, m.Chat.ID, i18n("vpn_enter_create_key_name"), i18n("vpn_key_name_placeholder"), h.createKey, bot)
 
 	case "vpn_delete_key":
 		keys, err := h.client.GetKeys(ctx, m.Chat.ID)
@@ -272,26 +304,18 @@ func (h *Handler) handleCallback(ctx context.Context, c *telegram.CallbackQuery,
 			return fmt.Errorf("failed to get keys: %w", err)
 		}
 
-		h.state.Set(m.Chat.ID, fmt.Sprintf("vpn_enter_existing_key_name_expected_%d", m.ID))
-
 		text := []string{i18n("vpn_enter_delete_key_name_top")}
 		for _, key := range keys {
 			text = append(text, i18n("vpn_enter_delete_key_name_item", key.Title))
 		}
 
-		buttons := [][]telegram.InlineKeyboardButton{
-			{{Text: i18n("vpn_button_cancel"), CallbackData: "vpn_back"}},
-		}
-		_, err = bot.EditMessageText(ctx, &telegram.EditMessageTextParams{
+		if _, err := bot.DeleteMessage(ctx, &telegram.DeleteMessageParams{
 			ChatID:    m.Chat.ID,
 			MessageID: m.ID,
-			Text:      strings.Join(text, "\n"),
-			ParseMode: telegram.ParseModeHTML,
-			ReplyMarkup: telegram.InlineKeyboardMarkup{
-				InlineKeyboard: buttons,
-			},
-		})
-		return err
+		}); err != nil {
+			return fmt.Errorf("failed to delete message: %w", err)
+		}
+		return h.prompt(ctx, m.Chat.ID, strings.Join(text, "\n"), i18n("vpn_key_name_placeholder"), h.deleteKey, bot)
 
 	case "vpn_manage_key":
 		keys, err := h.client.GetKeys(ctx, m.Chat.ID)
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"runtime"
 	"slices"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram/format"
 )
 
 var dict = map[string]string{
@@ -11,25 +13,27 @@ var dict = map[string]string{
 	"vpn_button_manage_key": "🔐 Управление ключами",
 	"vpn_button_remove_key": "❌ Удалить ключ",
 	"vpn_button_back":       "⏪ Назад",
-	"vpn_button_cancel":     "❌ Отмена",
+	"vpn_button_copy_key":   "📋 Скопировать ключ",
 	"vpn_enter_create_key_name": `Придумайте <b>любое имя</b> для ключа.
 	
 	Например:
 	<i>- iPhone</i>
 	<i>- Мой ключ</i>
 	
-	напишите имя в следующем сообщении`,
+	напишите имя в ответ на это сообщение`,
 	"vpn_enter_create_key_name_too_long": "Давайте придумаем что-то более лаконичное",
 	"vpn_enter_delete_key_name_top":      "Введите имя ключа, который хотите <b>удалить</b>\n",
 	"vpn_enter_delete_key_name_item":     "<code>%s</code>",
 	"vpn_key_created":                    "✅ Вы успешно создали новый ключ\n\n<code>%s</code>\n\nтеперь скопируйте ключ в буффер обмена (простым нажатием на него) и вставьте его в приложение",
 	"vpn_key_deleted":                    "✅ Ключ \"<i>%s</i>\" удалён!\n\n",
 	"vpn_key_not_found":                  "❌ Ключ не найден\n\n",
+	"vpn_key_name_placeholder":           "Имя ключа",
 	"vpn_key_list_top":                   "🔑 Активные ключи:\n",
 	"vpn_key_list_item":                  "<b>%d.</b> %s\n<code>%s</code>\n",
 	"vpn_key_list_bottom":                "\nВсего ключей: <b>%d</b>",
 	"vpn_mislead":                        "Неизвестная команда",
-	"vpn_unexpected_state":               "Возникла непредвиденная ошибка, попробуйте начать сначала\n\n/vpnhelp",
+	"vpn_prompt_cancel":                  "<i>/cancel — отменить</i>",
+	"vpn_prompt_expired":                 "Время ожидания ответа истекло, попробуйте начать сначала\n\n/vpnhelp",
 	"vpn_welcome": `🌏 <b>VPN всего за 3 простых шага</b>
 	
 	1️⃣ Установите любой <b>vless-совместимый</b> клиент на ваше устройство, например:
@@ -43,8 +47,15 @@ var dict = map[string]string{
 	3️⃣ Скопируйте полученный ключ в клиент`,
 }
 
+// i18n escapes string arguments, so they can't break the markup
 func i18n(key string, args ...any) string {
 	if val, ok := dict[key]; ok {
+		for i, arg := range args {
+			switch v := arg.(type) {
+			case string:
+				args[i] = format.EscapeHTML(v)
+			}
+		}
 		return fmt.Sprintf(val, args...)
 	}
 
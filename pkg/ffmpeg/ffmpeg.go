@@ -1,16 +1,20 @@
 package ffmpeg
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
+
+	"github.com/ailinykh/reposter/v3/pkg/shell"
 )
 
-func GetInfo(filePath string) (*Info, error) {
-	cmd := fmt.Sprintf(`ffprobe -v panic -of json -show_streams -show_format "%s"`, filePath)
-	out, err := exec.Command("/bin/sh", "-c", cmd).CombinedOutput()
+// TempDirPattern matches the directories processed files are written to
+const TempDirPattern = "ffmpeg*"
+
+func GetInfo(ctx context.Context, filePath string) (*Info, error) {
+	out, err := shell.Command(ctx, "ffprobe", "-v", "panic", "-of", "json", "-show_streams", "-show_format", filePath).CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read streams: %s", string(out))
 	}
@@ -24,16 +28,18 @@ func GetInfo(filePath string) (*Info, error) {
 	return &info, nil
 }
 
-func Crop(srcPath string, width, height int) (string, error) {
-	dirPath, err := os.MkdirTemp("", "ffmpeg*")
+// Crop writes the centered part of the image to a new directory within `dir`
+func Crop(ctx context.Context, dir, srcPath string, width, height int) (string, error) {
+	dirPath, err := os.MkdirTemp(dir, TempDirPattern)
 	if err != nil {
 		return "", fmt.Errorf("failed to create temporary directory: %w", err)
 	}
 
 	destPath := path.Join(dirPath, "cropped.jpg")
-	cmd := fmt.Sprintf(`ffmpeg -v error -i %s -vf "crop=%d:%d:(in_w-out_w)/2:(in_h-out_h)/2" -y %s`, srcPath, width, height, destPath)
-	_, err = exec.Command("/bin/sh", "-c", cmd).CombinedOutput()
+	crop := fmt.Sprintf("crop=%d:%d:(in_w-out_w)/2:(in_h-out_h)/2", width, height)
+	_, err = shell.Command(ctx, "ffmpeg", "-v", "error", "-i", srcPath, "-vf", crop, "-y", destPath).CombinedOutput()
 	if err != nil {
+		_ = os.RemoveAll(dirPath)
 		return "", fmt.Errorf("failed to crop image: %w", err)
 	}
 
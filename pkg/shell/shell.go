@@ -0,0 +1,20 @@
+package shell
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// WaitDelay is how long a cancelled command is given to release its output
+const WaitDelay = 5 * time.Second
+
+// Command runs the program with the arguments as is, no shell is involved,
+// so they never need quoting. When the context is done, the whole process tree
+// is killed, not only the program itself.
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = WaitDelay
+	killTree(cmd)
+	return cmd
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package shell
+
+import "os/exec"
+
+// killTree is a no-op, only the program itself is killed on cancel
+func killTree(cmd *exec.Cmd) {}
@@ -0,0 +1,14 @@
+package shell
+
+import "testing"
+
+func TestCommandArgs(t *testing.T) {
+	arg := "https://youtu.be/x;touch${IFS}/tmp/pwned&b=`id`"
+	out, err := Command(t.Context(), "echo", arg).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out); got != arg+"\n" {
+		t.Errorf("output = %q, want %q", got, arg+"\n")
+	}
+}
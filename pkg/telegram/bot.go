@@ -7,20 +7,25 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 )
 
 func NewBot(ctx context.Context, opts ...func(*Bot)) (*Bot, error) {
 	b := &Bot{
-		client:   http.DefaultClient,
-		endpoint: "https://api.telegram.org",
-		l:        slog.Default(),
+		client:      http.DefaultClient,
+		endpoint:    "https://api.telegram.org",
+		l:           slog.Default(),
+		retryPolicy: DefaultRetryPolicy,
+		rateLimits:  DefaultRateLimits,
 	}
 
 	for _, o := range opts {
 		o(b)
 	}
 
+	b.scheduler = newScheduler(b.rateLimits)
+
 	me, err := b.GetMe(ctx)
 	if err != nil {
 		return nil, err
@@ -33,10 +38,23 @@ func NewBot(ctx context.Context, opts ...func(*Bot)) (*Bot, error) {
 
 type Bot struct {
 	*User
-	client   *http.Client
-	endpoint string
-	token    string
-	l        *slog.Logger
+	client      *http.Client
+	endpoint    string
+	token       string
+	l           *slog.Logger
+	retryPolicy RetryPolicy
+	rateLimits  RateLimits
+	scheduler   *scheduler
+	local       bool
+}
+
+// UploadLimit is the maximum size of a file the bot is able to upload
+// https://core.telegram.org/bots/api#using-a-local-bot-api-server
+func (b *Bot) UploadLimit() int64 {
+	if b.local {
+		return 2_000_000_000
+	}
+	return 50_000_000 // multipart/form-data limit of the cloud Bot API
 }
 
 // GetUpdates https://core.telegram.org/bots/api#getupdates
@@ -47,6 +65,23 @@ func (b *Bot) GetUpdates(ctx context.Context, params *GetUpdatesParams) ([]*Upda
 	return rv, err
 }
 
+// SetWebhook https://core.telegram.org/bots/api#setwebhook
+func (b *Bot) SetWebhook(ctx context.Context, params *SetWebhookParams) error {
+	return b.raw(ctx, "setWebhook", params, nil)
+}
+
+// DeleteWebhook https://core.telegram.org/bots/api#deletewebhook
+func (b *Bot) DeleteWebhook(ctx context.Context, params *DeleteWebhookParams) error {
+	return b.raw(ctx, "deleteWebhook", params, nil)
+}
+
+// GetWebhookInfo https://core.telegram.org/bots/api#getwebhookinfo
+func (b *Bot) GetWebhookInfo(ctx context.Context) (*WebhookInfo, error) {
+	var rv *WebhookInfo
+	err := b.raw(ctx, "getWebhookInfo", nil, &rv)
+	return rv, err
+}
+
 // GetMe https://core.telegram.org/bots/api#getme
 func (b *Bot) GetMe(ctx context.Context) (*User, error) {
 	var rv *User
@@ -54,17 +89,35 @@ func (b *Bot) GetMe(ctx context.Context) (*User, error) {
 	return rv, err
 }
 
+// LogOut https://core.telegram.org/bots/api#logout
+func (b *Bot) LogOut(ctx context.Context) error {
+	return b.raw(ctx, "logOut", nil, nil)
+}
+
+// Close https://core.telegram.org/bots/api#close
+func (b *Bot) Close(ctx context.Context) error {
+	return b.raw(ctx, "close", nil, nil)
+}
+
 // SendMessage https://core.telegram.org/bots/api#sendmessage
 func (b *Bot) SendMessage(ctx context.Context, params *SendMessageParams) (*Message, error) {
+	inTopic(ctx, params.ChatID, &params.MessageThreadID)
 	var rv *Message
+	if err := b.scheduler.wait(ctx, params.ChatID, 1); err != nil {
+		return rv, err
+	}
 	err := b.raw(ctx, "sendMessage", params, &rv)
 	return rv, err
 }
 
 // SendPhoto https://core.telegram.org/bots/api#sendphoto
 func (b *Bot) SendPhoto(ctx context.Context, params *SendPhotoParams) (*Message, error) {
+	inTopic(ctx, params.ChatID, &params.MessageThreadID)
 	var rv *Message
-	if _, ok := params.Photo.(InputFileLocal); ok {
+	if err := b.scheduler.wait(ctx, params.ChatID, 1); err != nil {
+		return rv, err
+	}
+	if hasLocalFiles(params.Photo) {
 		err := b.rawMultipart(ctx, "sendPhoto", params, &rv)
 		return rv, err
 	}
@@ -74,8 +127,12 @@ func (b *Bot) SendPhoto(ctx context.Context, params *SendPhotoParams) (*Message,
 
 // SendVideo https://core.telegram.org/bots/api#sendvideo
 func (b *Bot) SendVideo(ctx context.Context, params *SendVideoParams) (*Message, error) {
+	inTopic(ctx, params.ChatID, &params.MessageThreadID)
 	var rv *Message
-	if _, ok := params.Video.(InputFileLocal); ok {
+	if err := b.scheduler.wait(ctx, params.ChatID, 1); err != nil {
+		return rv, err
+	}
+	if hasLocalFiles(params.Video, params.Thumbnail) {
 		err := b.rawMultipart(ctx, "sendVideo", params, &rv)
 		return rv, err
 	}
@@ -83,10 +140,85 @@ func (b *Bot) SendVideo(ctx context.Context, params *SendVideoParams) (*Message,
 	return rv, err
 }
 
+// SendAnimation https://core.telegram.org/bots/api#sendanimation
+func (b *Bot) SendAnimation(ctx context.Context, params *SendAnimationParams) (*Message, error) {
+	inTopic(ctx, params.ChatID, &params.MessageThreadID)
+	var rv *Message
+	if err := b.scheduler.wait(ctx, params.ChatID, 1); err != nil {
+		return rv, err
+	}
+	if hasLocalFiles(params.Animation, params.Thumbnail) {
+		err := b.rawMultipart(ctx, "sendAnimation", params, &rv)
+		return rv, err
+	}
+	err := b.raw(ctx, "sendAnimation", params, &rv)
+	return rv, err
+}
+
+// SendAudio https://core.telegram.org/bots/api#sendaudio
+func (b *Bot) SendAudio(ctx context.Context, params *SendAudioParams) (*Message, error) {
+	inTopic(ctx, params.ChatID, &params.MessageThreadID)
+	var rv *Message
+	if err := b.scheduler.wait(ctx, params.ChatID, 1); err != nil {
+		return rv, err
+	}
+	if hasLocalFiles(params.Audio, params.Thumbnail) {
+		err := b.rawMultipart(ctx, "sendAudio", params, &rv)
+		return rv, err
+	}
+	err := b.raw(ctx, "sendAudio", params, &rv)
+	return rv, err
+}
+
+// SendDocument https://core.telegram.org/bots/api#senddocument
+func (b *Bot) SendDocument(ctx context.Context, params *SendDocumentParams) (*Message, error) {
+	inTopic(ctx, params.ChatID, &params.MessageThreadID)
+	var rv *Message
+	if err := b.scheduler.wait(ctx, params.ChatID, 1); err != nil {
+		return rv, err
+	}
+	if hasLocalFiles(params.Document, params.Thumbnail) {
+		err := b.rawMultipart(ctx, "sendDocument", params, &rv)
+		return rv, err
+	}
+	err := b.raw(ctx, "sendDocument", params, &rv)
+	return rv, err
+}
+
+// SendVoice https://core.telegram.org/bots/api#sendvoice
+func (b *Bot) SendVoice(ctx context.Context, params *SendVoiceParams) (*Message, error) {
+	inTopic(ctx, params.ChatID, &params.MessageThreadID)
+	var rv *Message
+	if err := b.scheduler.wait(ctx, params.ChatID, 1); err != nil {
+		return rv, err
+	}
+	if hasLocalFiles(params.Voice) {
+		err := b.rawMultipart(ctx, "sendVoice", params, &rv)
+		return rv, err
+	}
+	err := b.raw(ctx, "sendVoice", params, &rv)
+	return rv, err
+}
+
 // SendMediaGroup https://core.telegram.org/bots/api#sendmediagroup
 func (b *Bot) SendMediaGroup(ctx context.Context, params *SendMediaGroupParams) ([]*Message, error) {
+	inTopic(ctx, params.ChatID, &params.MessageThreadID)
 	var rv []*Message
-	err := b.raw(ctx, "sendMediaGroup", params, &rv)
+	var files map[string]any
+	// every item of an album counts as a separate message
+	if err := b.scheduler.wait(ctx, params.ChatID, len(params.Media)); err != nil {
+		return rv, err
+	}
+	p := *params
+	p.Media, files = b.attach(params.Media)
+	if len(files) > 0 {
+		m := map[string]any{}
+		fill(m, &p)
+		maps.Copy(m, files)
+		err := b.rawForm(ctx, "sendMediaGroup", m, &rv)
+		return rv, err
+	}
+	err := b.raw(ctx, "sendMediaGroup", &p, &rv)
 	return rv, err
 }
 
@@ -103,7 +235,14 @@ func (b *Bot) IsUserMemberOfChat(ctx context.Context, params *GetChatMemberParam
 		b.l.Error("failed to get ChatMember", "error", err)
 		return false
 	}
-	return chatMember != nil && chatMember.Status != "left" && chatMember.Status != "kicked"
+	return chatMember != nil && chatMember.Present()
+}
+
+// GetFile https://core.telegram.org/bots/api#getfile
+func (b *Bot) GetFile(ctx context.Context, params *GetFileParams) (*File, error) {
+	var rv *File
+	err := b.raw(ctx, "getFile", params, &rv)
+	return rv, err
 }
 
 // AnswerCallbackQuery https://core.telegram.org/bots/api#answercallbackquery
@@ -111,9 +250,27 @@ func (b *Bot) AnswerCallbackQuery(ctx context.Context, params *AnswerCallbackQue
 	return b.raw(ctx, "answerCallbackQuery", params, nil)
 }
 
+// AnswerInlineQuery https://core.telegram.org/bots/api#answerinlinequery
+func (b *Bot) AnswerInlineQuery(ctx context.Context, params *AnswerInlineQueryParams) error {
+	return b.raw(ctx, "answerInlineQuery", params, nil)
+}
+
+// SetMyCommands https://core.telegram.org/bots/api#setmycommands
+func (b *Bot) SetMyCommands(ctx context.Context, params *SetMyCommandsParams) error {
+	return b.raw(ctx, "setMyCommands", params, nil)
+}
+
+// DeleteMyCommands https://core.telegram.org/bots/api#deletemycommands
+func (b *Bot) DeleteMyCommands(ctx context.Context, params *DeleteMyCommandsParams) error {
+	return b.raw(ctx, "deleteMyCommands", params, nil)
+}
+
 // EditMessageText https://core.telegram.org/bots/api#editmessagetext
 func (b *Bot) EditMessageText(ctx context.Context, params *EditMessageTextParams) (*Message, error) {
 	var rv *Message
+	if err := b.scheduler.wait(ctx, params.ChatID, 1); err != nil {
+		return rv, err
+	}
 	err := b.raw(ctx, "editMessageText", params, &rv)
 	return rv, err
 }
@@ -127,29 +284,51 @@ func (b *Bot) DeleteMessage(ctx context.Context, params *DeleteMessageParams) (b
 
 func (b *Bot) raw(ctx context.Context, method string, out, in any) error {
 	url := b.endpoint + "/bot" + b.token + "/" + method
-	var body io.Reader
-	if out != nil {
+	var data []byte
+	encode := func() error {
+		if out == nil {
+			return nil
+		}
 		buf := new(bytes.Buffer)
 		err := json.NewEncoder(buf).Encode(&out)
 		if err != nil {
 			return fmt.Errorf("failed to pack data %w", err)
 		}
-		body = io.NopCloser(buf)
+		data = buf.Bytes()
+		return nil
+	}
+	if err := encode(); err != nil {
+		return err
 	}
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	migrate := func(chatID int64) bool {
+		return setChatID(out, chatID) && encode() == nil
 	}
 
-	request.Header.Add("Content-Type", "application/json")
+	return b.withRetry(ctx, b.retryPolicy, method, func() error {
+		var body io.Reader
+		if data != nil {
+			body = bytes.NewReader(data)
+		}
 
-	resp, err := b.client.Do(request)
-	if err != nil {
-		return fmt.Errorf("failed to perform request: %w", err)
-	}
-	defer resp.Body.Close()
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		request.Header.Add("Content-Type", "application/json")
+
+		resp, err := b.client.Do(request)
+		if err != nil {
+			return fmt.Errorf("failed to perform request: %w", err)
+		}
+		defer resp.Body.Close()
 
+		return decodeResponse(method, resp, in)
+	}, migrate)
+}
+
+func decodeResponse(method string, resp *http.Response, in any) error {
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("failed to read body: %w", err)
@@ -157,11 +336,19 @@ func (b *Bot) raw(ctx context.Context, method string, out, in any) error {
 
 	var r apiResponse
 	if err = json.Unmarshal(data, &r); err != nil {
+		if resp.StatusCode >= http.StatusInternalServerError {
+			// e.g. bad gateway from a reverse proxy in front of the Bot API
+			return newError(method, resp.StatusCode, &r)
+		}
 		return fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	if !r.OK {
-		return fmt.Errorf("telegram error: %s", r.Description)
+		return newError(method, resp.StatusCode, &r)
+	}
+
+	if in == nil {
+		return nil
 	}
 
 	return json.Unmarshal(r.Result, in)
@@ -176,6 +363,6 @@ type apiResponse struct {
 }
 
 type apiResponseParams struct {
-	RetryAfter      int `json:"retry_after,omitempty"`
-	MigrateToChatID int `json:"migrate_to_chat_id,omitempty"`
+	RetryAfter      int   `json:"retry_after,omitempty"`
+	MigrateToChatID int64 `json:"migrate_to_chat_id,omitempty"`
 }
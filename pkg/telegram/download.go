@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// DownloadFile writes the content of a file sent by a user to `w`.
+// The cloud Bot API serves files up to 20 MB only, a local one has no limits.
+// https://core.telegram.org/bots/api#getfile
+func (b *Bot) DownloadFile(ctx context.Context, fileID string, w io.Writer) error {
+	file, err := b.GetFile(ctx, &GetFileParams{FileID: fileID})
+	if err != nil {
+		return fmt.Errorf("failed to get file: %w", err)
+	}
+
+	if file.FilePath == "" {
+		return fmt.Errorf("file %s is not available for download", fileID)
+	}
+
+	// a local Bot API server returns an absolute path on its own disk
+	if b.local && filepath.IsAbs(file.FilePath) {
+		f, err := os.Open(file.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to open local file: %w", err)
+		}
+		defer f.Close()
+
+		if _, err = io.Copy(w, f); err != nil {
+			return fmt.Errorf("failed to copy local file: %w", err)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.FileURL(file), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to perform request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d for file %s", res.StatusCode, fileID)
+	}
+
+	if _, err = io.Copy(w, res.Body); err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	return nil
+}
+
+// FileURL https://core.telegram.org/bots/api#file
+func (b *Bot) FileURL(file *File) string {
+	return b.endpoint + "/file/bot" + b.token + "/" + file.FilePath
+}
@@ -0,0 +1,64 @@
+package telegram
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Error https://core.telegram.org/bots/api#making-requests
+type Error struct {
+	Method          string
+	Code            int
+	Description     string
+	RetryAfter      time.Duration
+	MigrateToChatID int64
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("telegram error: %s: %s", e.Method, e.Description)
+}
+
+// TooManyRequests reports a flood wait, see RetryAfter for how long to wait
+func (e *Error) TooManyRequests() bool {
+	return e.Code == http.StatusTooManyRequests
+}
+
+// ServerError reports a failure on the Telegram side which is worth retrying
+func (e *Error) ServerError() bool {
+	return e.Code >= http.StatusInternalServerError
+}
+
+// Conflict reports another getUpdates consumer or an active webhook
+func (e *Error) Conflict() bool {
+	return e.Code == http.StatusConflict
+}
+
+// WebhookActive reports getUpdates being called while an outgoing webhook is set up
+func (e *Error) WebhookActive() bool {
+	return e.Conflict() && strings.Contains(e.Description, "webhook is active")
+}
+
+func newError(method string, statusCode int, r *apiResponse) *Error {
+	e := &Error{
+		Method:      method,
+		Code:        r.ErrorCode,
+		Description: r.Description,
+	}
+
+	if e.Code == 0 {
+		e.Code = statusCode
+	}
+
+	if e.Description == "" {
+		e.Description = http.StatusText(statusCode)
+	}
+
+	if r.Parameters != nil {
+		e.RetryAfter = time.Duration(r.Parameters.RetryAfter) * time.Second
+		e.MigrateToChatID = r.Parameters.MigrateToChatID
+	}
+
+	return e
+}
@@ -0,0 +1,175 @@
+// Package format builds formatted texts for messages and captions.
+//
+// Every piece of text is escaped according to the parse mode,
+// so titles, names and descriptions can't break the markup.
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+// Telegram limits the length of a text after entities parsing
+// https://core.telegram.org/bots/api#sendmessage
+const (
+	CaptionLimit = 1024
+	MessageLimit = 4096
+)
+
+// Ellipsis ends a truncated text
+const Ellipsis = "…"
+
+// Node is a piece of formatted text
+type Node interface {
+	write(w *writer)
+}
+
+// Text is a plain text
+func Text(s string) Node {
+	return text(s)
+}
+
+// Textf is a plain text formatted with fmt.Sprintf
+func Textf(format string, a ...any) Node {
+	return text(fmt.Sprintf(format, a...))
+}
+
+// Join concatenates nodes with the separator in between
+func Join(nodes []Node, sep string) Node {
+	var rv group
+	for i, n := range nodes {
+		if i > 0 {
+			rv = append(rv, text(sep))
+		}
+		rv = append(rv, n)
+	}
+	return rv
+}
+
+// Bold https://core.telegram.org/bots/api#formatting-options
+func Bold(nodes ...Node) Node {
+	return &tag{html: "b", markdown: "*", nodes: nodes}
+}
+
+// Italic https://core.telegram.org/bots/api#formatting-options
+func Italic(nodes ...Node) Node {
+	return &tag{html: "i", markdown: "_", nodes: nodes}
+}
+
+// Code is an inline fixed-width text
+func Code(s string) Node {
+	return &code{text: s}
+}
+
+// Pre is a fixed-width block, `language` is optional
+func Pre(s, language string) Node {
+	return &code{text: s, language: language, block: true}
+}
+
+// Link is an inline URL
+func Link(url string, nodes ...Node) Node {
+	return &link{url: url, nodes: nodes}
+}
+
+// Mention links to a user without a username
+func Mention(userID int64, name string) Node {
+	return &link{url: "tg://user?id=" + strconv.FormatInt(userID, 10), nodes: []Node{text(name)}}
+}
+
+// HTML renders nodes for telegram.ParseModeHTML
+func HTML(nodes ...Node) string {
+	return Render(telegram.ParseModeHTML, 0, nodes...)
+}
+
+// Caption renders nodes for telegram.ParseModeHTML within CaptionLimit
+func Caption(nodes ...Node) string {
+	return Render(telegram.ParseModeHTML, CaptionLimit, nodes...)
+}
+
+// MarkdownV2 renders nodes for telegram.ParseModeMarkdown
+func MarkdownV2(nodes ...Node) string {
+	return Render(telegram.ParseModeMarkdown, 0, nodes...)
+}
+
+// Plain renders the text the way a user sees it
+func Plain(nodes ...Node) string {
+	return Render("", 0, nodes...)
+}
+
+// Len returns the length of the text after entities parsing in UTF-16 code units
+func Len(nodes ...Node) int {
+	return len(utf16.Encode([]rune(Plain(nodes...))))
+}
+
+// Render renders nodes for the parse mode. The text is truncated and ends with Ellipsis
+// if it's longer than `limit` UTF-16 code units, every open tag is closed anyway.
+// Zero `limit` means no limit.
+func Render(mode telegram.ParseMode, limit int, nodes ...Node) string {
+	w := &writer{mode: mode, budget: -1}
+	truncated := limit > 0 && Len(nodes...) > limit
+	if truncated {
+		w.budget = limit - len(utf16.Encode([]rune(Ellipsis)))
+	}
+
+	group(nodes).write(w)
+
+	if truncated {
+		w.WriteString(Ellipsis)
+	}
+	return w.String()
+}
+
+// EscapeHTML https://core.telegram.org/bots/api#html-style
+func EscapeHTML(s string) string {
+	return htmlReplacer.Replace(s)
+}
+
+// EscapeMarkdownV2 https://core.telegram.org/bots/api#markdownv2-style
+func EscapeMarkdownV2(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+var htmlReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+)
+
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"_", `\_`,
+	"*", `\*`,
+	"[", `\[`,
+	"]", `\]`,
+	"(", `\(`,
+	")", `\)`,
+	"~", `\~`,
+	"`", "\\`",
+	">", `\>`,
+	"#", `\#`,
+	"+", `\+`,
+	"-", `\-`,
+	"=", `\=`,
+	"|", `\|`,
+	"{", `\{`,
+	"}", `\}`,
+	".", `\.`,
+	"!", `\!`,
+)
+
+// inside of `pre` and `code` entities
+var markdownCodeReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+)
+
+// inside of the (...) part of inline links
+var markdownLinkReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	")", `\)`,
+)
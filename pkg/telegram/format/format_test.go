@@ -0,0 +1,66 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  telegram.ParseMode
+		limit int
+		nodes []Node
+		want  string
+	}{
+		{"html escaping", telegram.ParseModeHTML, 0, []Node{Bold(Text(`<a & "b">`))}, "<b>&lt;a &amp; &quot;b&quot;&gt;</b>"},
+		{"markdown escaping", telegram.ParseModeMarkdown, 0, []Node{Italic(Text("1.5 (a_b)!"))}, `_1\.5 \(a\_b\)\!_`},
+		{"markdown code", telegram.ParseModeMarkdown, 0, []Node{Code("a`b\\c.")}, "`a\\`b\\\\c.`"},
+		{"markdown link", telegram.ParseModeMarkdown, 0, []Node{Link("https://x.com/a)b", Text("x"))}, `[x](https://x.com/a\)b)`},
+		{"html link", telegram.ParseModeHTML, 0, []Node{Link(`https://x.com/?a=1&b="2"`, Text("x"))}, `<a href="https://x.com/?a=1&amp;b=&quot;2&quot;">x</a>`},
+		{"plain", "", 0, []Node{Bold(Text("<b>")), Text(" & "), Code("x")}, "<b> & x"},
+		{"fits the limit", telegram.ParseModeHTML, 5, []Node{Bold(Text("hello"))}, "<b>hello</b>"},
+		{"truncated tags are closed", telegram.ParseModeHTML, 5, []Node{Bold(Text("hello "), Italic(Text("world")))}, "<b>hell</b>…"},
+		{"truncated inside a tag", telegram.ParseModeHTML, 8, []Node{Bold(Text("hello "), Italic(Text("world")))}, "<b>hello <i>w</i></b>…"},
+		{"escaping doesn't count", telegram.ParseModeHTML, 4, []Node{Text("a&b&c")}, "a&amp;b…"},
+		{"surrogate pair is not split", telegram.ParseModeHTML, 4, []Node{Text("😀😀😀")}, "😀…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.mode, tt.limit, tt.nodes...); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLen(t *testing.T) {
+	tests := []struct {
+		nodes []Node
+		want  int
+	}{
+		{[]Node{Text("hello")}, 5},
+		{[]Node{Bold(Text("a&b"))}, 3},
+		{[]Node{Text("😀")}, 2},
+		{[]Node{Link("https://example.com", Text("x"))}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := Len(tt.nodes...); got != tt.want {
+			t.Errorf("Len(%q) = %d, want %d", Plain(tt.nodes...), got, tt.want)
+		}
+	}
+}
+
+func TestCaption(t *testing.T) {
+	nodes := []Node{Bold(Text(strings.Repeat("a", CaptionLimit))), Text("b")}
+	if caption := Caption(nodes...); !strings.HasSuffix(caption, "</b>"+Ellipsis) {
+		t.Errorf("caption ends with %q", caption[len(caption)-10:])
+	}
+	if n := Len(Text(Render("", CaptionLimit, nodes...))); n != CaptionLimit {
+		t.Errorf("caption is %d units long, want %d", n, CaptionLimit)
+	}
+}
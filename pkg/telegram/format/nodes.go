@@ -0,0 +1,139 @@
+package format
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+type writer struct {
+	strings.Builder
+	mode telegram.ParseMode
+	// budget is the number of UTF-16 code units of text left, -1 means unlimited
+	budget int
+}
+
+// text writes as much of `s` as the budget allows
+func (w *writer) text(s string, escape func(string) string) {
+	if w.budget >= 0 {
+		units := utf16.Encode([]rune(s))
+		if len(units) > w.budget {
+			units = units[:w.budget]
+			// never split a surrogate pair
+			if n := len(units); n > 0 && utf16.IsSurrogate(rune(units[n-1])) {
+				units = units[:n-1]
+			}
+			s = string(utf16.Decode(units))
+		}
+		w.budget -= len(units)
+	}
+
+	if w.mode != "" {
+		s = escape(s)
+	}
+	w.WriteString(s)
+}
+
+// markup writes a tag which doesn't count towards the text length
+func (w *writer) markup(html, markdown string) {
+	switch w.mode {
+	case telegram.ParseModeHTML:
+		w.WriteString(html)
+	case telegram.ParseModeMarkdown:
+		w.WriteString(markdown)
+	}
+}
+
+func (w *writer) escape() func(string) string {
+	if w.mode == telegram.ParseModeMarkdown {
+		return EscapeMarkdownV2
+	}
+	return EscapeHTML
+}
+
+func (w *writer) exhausted() bool {
+	return w.budget == 0
+}
+
+type text string
+
+func (t text) write(w *writer) {
+	w.text(string(t), w.escape())
+}
+
+type group []Node
+
+func (g group) write(w *writer) {
+	for _, n := range g {
+		if w.exhausted() {
+			return
+		}
+		n.write(w)
+	}
+}
+
+type tag struct {
+	html     string
+	markdown string
+	nodes    []Node
+}
+
+func (t *tag) write(w *writer) {
+	if w.exhausted() {
+		return
+	}
+	w.markup("<"+t.html+">", t.markdown)
+	group(t.nodes).write(w)
+	w.markup("</"+t.html+">", t.markdown)
+}
+
+type code struct {
+	text     string
+	language string
+	block    bool
+}
+
+func (c *code) write(w *writer) {
+	if w.exhausted() {
+		return
+	}
+
+	switch {
+	case c.block && c.language != "":
+		w.markup(`<pre><code class="language-`+EscapeHTML(c.language)+`">`, "```"+c.language+"\n")
+	case c.block:
+		w.markup("<pre>", "```\n")
+	default:
+		w.markup("<code>", "`")
+	}
+
+	escape := EscapeHTML
+	if w.mode == telegram.ParseModeMarkdown {
+		escape = markdownCodeReplacer.Replace
+	}
+	w.text(c.text, escape)
+
+	switch {
+	case c.block && c.language != "":
+		w.markup("</code></pre>", "\n```")
+	case c.block:
+		w.markup("</pre>", "\n```")
+	default:
+		w.markup("</code>", "`")
+	}
+}
+
+type link struct {
+	url   string
+	nodes []Node
+}
+
+func (l *link) write(w *writer) {
+	if w.exhausted() {
+		return
+	}
+	w.markup(`<a href="`+EscapeHTML(l.url)+`">`, "[")
+	group(l.nodes).write(w)
+	w.markup("</a>", "]("+markdownLinkReplacer.Replace(l.url)+")")
+}
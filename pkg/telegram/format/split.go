@@ -0,0 +1,52 @@
+package format
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// Cut splits the plain text at the last paragraph, line or word boundary
+// so the head is at most `limit` UTF-16 code units long
+func Cut(s string, limit int) (head, tail string) {
+	n, units := 0, 0
+	for i, r := range s {
+		units += utf16.RuneLen(r)
+		if units > limit {
+			break
+		}
+		n = i + len(string(r))
+	}
+
+	if n == len(s) {
+		return s, ""
+	}
+
+	prefix := s[:n]
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		// a separator within the leading whitespace leaves nothing to cut off
+		if i := strings.LastIndex(prefix, sep); i > 0 {
+			if head := strings.TrimRight(s[:i], " \n"); head != "" {
+				return head, strings.TrimLeft(s[i:], " \n")
+			}
+		}
+	}
+
+	// a single word is too long, nothing to do but to break it
+	return prefix, s[n:]
+}
+
+// Split splits the plain text into chunks of at most `limit` UTF-16 code units,
+// paragraphs are kept together whenever possible
+func Split(s string, limit int) []string {
+	var chunks []string
+	for s != "" {
+		head, tail := Cut(s, limit)
+		if head == "" {
+			// the limit is too small for a single rune
+			return append(chunks, tail)
+		}
+		chunks = append(chunks, head)
+		s = tail
+	}
+	return chunks
+}
@@ -0,0 +1,64 @@
+package format
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestCut(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		limit int
+		head  string
+		tail  string
+	}{
+		{"fits", "hello world", 20, "hello world", ""},
+		{"word boundary", "hello world", 8, "hello", "world"},
+		{"line boundary", "hello\nbig world", 12, "hello", "big world"},
+		{"paragraph boundary", "one two\n\nthree four", 15, "one two", "three four"},
+		{"long word", "abcdefghij", 4, "abcd", "efghij"},
+		{"leading whitespace", "  abcdefghij", 6, "  abcd", "efghij"},
+		{"surrogate pair", "😀😀😀", 5, "😀😀", "😀"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, tail := Cut(tt.s, tt.limit)
+			if head != tt.head || tail != tt.tail {
+				t.Errorf("Cut(%q, %d) = %q, %q, want %q, %q", tt.s, tt.limit, head, tail, tt.head, tt.tail)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		limit  int
+		chunks []string
+	}{
+		{"empty", "", 10, nil},
+		{"fits", "hello", 10, []string{"hello"}},
+		{"words", "one two three four", 9, []string{"one two", "three", "four"}},
+		{"paragraphs", "one\n\ntwo three", 10, []string{"one", "two three"}},
+		{"leading whitespace", " \n" + strings.Repeat("a", 12), 5, []string{" \naaa", "aaaaa", "aaaa"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Split(tt.s, tt.limit)
+			if !slices.Equal(chunks, tt.chunks) {
+				t.Errorf("Split(%q, %d) = %q, want %q", tt.s, tt.limit, chunks, tt.chunks)
+			}
+			for _, c := range chunks {
+				if n := len(utf16.Encode([]rune(c))); n > tt.limit {
+					t.Errorf("chunk %q is %d units long, over the limit", c, n)
+				}
+			}
+		})
+	}
+}
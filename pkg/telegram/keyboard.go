@@ -1,24 +1,98 @@
 package telegram
 
-func NewInlineKeyboard(keyboard [][]map[string]any) map[string]any {
-	return map[string]any{
-		"reply_markup": map[string]any{
-			"inline_keyboard": keyboard,
-		},
-	}
-}
-
-func NewKeyboard(keyboard [][]map[string]any) map[string]any {
-	if len(keyboard) == 0 {
-		return map[string]any{
-			"reply_markup": map[string]any{
-				"remove_keyboard": true,
-			},
-		}
-	}
-	return map[string]any{
-		"reply_markup": map[string]any{
-			"keyboard": keyboard,
-		},
-	}
+import "encoding/json"
+
+// ReplyMarkup is one of InlineKeyboardMarkup, ReplyKeyboardMarkup, ReplyKeyboardRemove or ForceReply
+type ReplyMarkup interface {
+	isReplyMarkup() // marker interface
+}
+
+// InlineKeyboardMarkup https://core.telegram.org/bots/api#inlinekeyboardmarkup
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+func (InlineKeyboardMarkup) isReplyMarkup() {}
+
+// InlineKeyboardButton https://core.telegram.org/bots/api#inlinekeyboardbutton
+//
+// Exactly one of the optional fields must be used. An empty string is meaningful
+// for the switch_inline_query fields, so they are pointers.
+type InlineKeyboardButton struct {
+	Text                         string          `json:"text"`
+	URL                          string          `json:"url,omitempty"`
+	CallbackData                 string          `json:"callback_data,omitempty"`
+	WebApp                       *WebAppInfo     `json:"web_app,omitempty"`
+	LoginURL                     *LoginURL       `json:"login_url,omitempty"`
+	SwitchInlineQuery            *string         `json:"switch_inline_query,omitempty"`
+	SwitchInlineQueryCurrentChat *string         `json:"switch_inline_query_current_chat,omitempty"`
+	CopyText                     *CopyTextButton `json:"copy_text,omitempty"`
+}
+
+// WebAppInfo https://core.telegram.org/bots/api#webappinfo
+type WebAppInfo struct {
+	URL string `json:"url"`
+}
+
+// LoginURL https://core.telegram.org/bots/api#loginurl
+type LoginURL struct {
+	URL                string `json:"url"`
+	ForwardText        string `json:"forward_text,omitempty"`
+	BotUsername        string `json:"bot_username,omitempty"`
+	RequestWriteAccess bool   `json:"request_write_access,omitempty"`
+}
+
+// CopyTextButton https://core.telegram.org/bots/api#copytextbutton
+type CopyTextButton struct {
+	Text string `json:"text"`
+}
+
+// ReplyKeyboardMarkup https://core.telegram.org/bots/api#replykeyboardmarkup
+type ReplyKeyboardMarkup struct {
+	Keyboard              [][]KeyboardButton `json:"keyboard"`
+	IsPersistent          bool               `json:"is_persistent,omitempty"`
+	ResizeKeyboard        bool               `json:"resize_keyboard,omitempty"`
+	OneTimeKeyboard       bool               `json:"one_time_keyboard,omitempty"`
+	InputFieldPlaceholder string             `json:"input_field_placeholder,omitempty"`
+	Selective             bool               `json:"selective,omitempty"`
+}
+
+func (ReplyKeyboardMarkup) isReplyMarkup() {}
+
+// KeyboardButton https://core.telegram.org/bots/api#keyboardbutton
+type KeyboardButton struct {
+	Text            string      `json:"text"`
+	RequestContact  bool        `json:"request_contact,omitempty"`
+	RequestLocation bool        `json:"request_location,omitempty"`
+	WebApp          *WebAppInfo `json:"web_app,omitempty"`
+}
+
+// ReplyKeyboardRemove https://core.telegram.org/bots/api#replykeyboardremove
+type ReplyKeyboardRemove struct {
+	Selective bool
+}
+
+func (ReplyKeyboardRemove) isReplyMarkup() {}
+
+func (r ReplyKeyboardRemove) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		RemoveKeyboard bool `json:"remove_keyboard"`
+		Selective      bool `json:"selective,omitempty"`
+	}{true, r.Selective})
+}
+
+// ForceReply https://core.telegram.org/bots/api#forcereply
+type ForceReply struct {
+	InputFieldPlaceholder string
+	Selective             bool
+}
+
+func (ForceReply) isReplyMarkup() {}
+
+func (r ForceReply) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ForceReply            bool   `json:"force_reply"`
+		InputFieldPlaceholder string `json:"input_field_placeholder,omitempty"`
+		Selective             bool   `json:"selective,omitempty"`
+	}{true, r.InputFieldPlaceholder, r.Selective})
 }
@@ -8,9 +8,25 @@ type GetUpdatesParams struct {
 	AllowedUpdates []string `json:"allowed_updates,omitempty"`
 }
 
+// SetWebhookParams https://core.telegram.org/bots/api#setwebhook
+type SetWebhookParams struct {
+	URL                string   `json:"url"`
+	IPAddress          string   `json:"ip_address,omitempty"`
+	MaxConnections     int      `json:"max_connections,omitempty"`
+	AllowedUpdates     []string `json:"allowed_updates,omitempty"`
+	DropPendingUpdates bool     `json:"drop_pending_updates,omitempty"`
+	SecretToken        string   `json:"secret_token,omitempty"`
+}
+
+// DeleteWebhookParams https://core.telegram.org/bots/api#deletewebhook
+type DeleteWebhookParams struct {
+	DropPendingUpdates bool `json:"drop_pending_updates,omitempty"`
+}
+
 // SendMessageParams https://core.telegram.org/bots/api#sendmessage
 type SendMessageParams struct {
 	ChatID             int64               `json:"chat_id"`
+	MessageThreadID    int64               `json:"message_thread_id,omitempty"`
 	Text               string              `json:"text"`
 	ParseMode          ParseMode           `json:"parse_mode,omitempty"`
 	LinkPreviewOptions *LinkPreviewOptions `json:"link_preview_options,omitempty"`
@@ -20,28 +36,81 @@ type SendMessageParams struct {
 
 // SendPhotoParams https://core.telegram.org/bots/api#sendphoto
 type SendPhotoParams struct {
-	ChatID    int64     `json:"chat_id"`
-	Photo     InputFile `json:"photo"`
-	Caption   string    `json:"caption,omitempty"`
-	ParseMode ParseMode `json:"parse_mode,omitempty"`
+	ChatID                int64     `json:"chat_id"`
+	MessageThreadID       int64     `json:"message_thread_id,omitempty"`
+	Photo                 InputFile `json:"photo"`
+	Caption               string    `json:"caption,omitempty"`
+	ParseMode             ParseMode `json:"parse_mode,omitempty"`
+	ShowCaptionAboveMedia bool      `json:"show_caption_above_media,omitempty"`
 }
 
 // SendVideoParams https://core.telegram.org/bots/api#sendvideo
 type SendVideoParams struct {
-	ChatID            int64     `json:"chat_id"`
-	Video             InputFile `json:"video"`
-	Duration          int       `json:"duration,omitempty"`
-	Width             int       `json:"width,omitempty"`
-	Height            int       `json:"height,omitempty"`
-	Thumbnail         InputFile `json:"thumbnail,omitempty"`
-	Caption           string    `json:"caption,omitempty"`
-	ParseMode         ParseMode `json:"parse_mode,omitempty"`
-	SupportsStreaming bool      `json:"supports_streaming,omitempty"`
+	ChatID                int64     `json:"chat_id"`
+	MessageThreadID       int64     `json:"message_thread_id,omitempty"`
+	Video                 InputFile `json:"video"`
+	Duration              int       `json:"duration,omitempty"`
+	Width                 int       `json:"width,omitempty"`
+	Height                int       `json:"height,omitempty"`
+	Thumbnail             InputFile `json:"thumbnail,omitempty"`
+	Caption               string    `json:"caption,omitempty"`
+	ParseMode             ParseMode `json:"parse_mode,omitempty"`
+	ShowCaptionAboveMedia bool      `json:"show_caption_above_media,omitempty"`
+	SupportsStreaming     bool      `json:"supports_streaming,omitempty"`
+}
+
+// SendAnimationParams https://core.telegram.org/bots/api#sendanimation
+type SendAnimationParams struct {
+	ChatID                int64     `json:"chat_id"`
+	MessageThreadID       int64     `json:"message_thread_id,omitempty"`
+	Animation             InputFile `json:"animation"`
+	Duration              int       `json:"duration,omitempty"`
+	Width                 int       `json:"width,omitempty"`
+	Height                int       `json:"height,omitempty"`
+	Thumbnail             InputFile `json:"thumbnail,omitempty"`
+	Caption               string    `json:"caption,omitempty"`
+	ParseMode             ParseMode `json:"parse_mode,omitempty"`
+	ShowCaptionAboveMedia bool      `json:"show_caption_above_media,omitempty"`
+}
+
+// SendAudioParams https://core.telegram.org/bots/api#sendaudio
+type SendAudioParams struct {
+	ChatID          int64     `json:"chat_id"`
+	MessageThreadID int64     `json:"message_thread_id,omitempty"`
+	Audio           InputFile `json:"audio"`
+	Caption         string    `json:"caption,omitempty"`
+	ParseMode       ParseMode `json:"parse_mode,omitempty"`
+	Duration        int       `json:"duration,omitempty"`
+	Performer       string    `json:"performer,omitempty"`
+	Title           string    `json:"title,omitempty"`
+	Thumbnail       InputFile `json:"thumbnail,omitempty"`
+}
+
+// SendDocumentParams https://core.telegram.org/bots/api#senddocument
+type SendDocumentParams struct {
+	ChatID                      int64     `json:"chat_id"`
+	MessageThreadID             int64     `json:"message_thread_id,omitempty"`
+	Document                    InputFile `json:"document"`
+	Thumbnail                   InputFile `json:"thumbnail,omitempty"`
+	Caption                     string    `json:"caption,omitempty"`
+	ParseMode                   ParseMode `json:"parse_mode,omitempty"`
+	DisableContentTypeDetection bool      `json:"disable_content_type_detection,omitempty"`
+}
+
+// SendVoiceParams https://core.telegram.org/bots/api#sendvoice
+type SendVoiceParams struct {
+	ChatID          int64     `json:"chat_id"`
+	MessageThreadID int64     `json:"message_thread_id,omitempty"`
+	Voice           InputFile `json:"voice"`
+	Caption         string    `json:"caption,omitempty"`
+	ParseMode       ParseMode `json:"parse_mode,omitempty"`
+	Duration        int       `json:"duration,omitempty"`
 }
 
 // SendMediaGroupParams https://core.telegram.org/bots/api#sendmediagroup
 type SendMediaGroupParams struct {
 	ChatID          int64            `json:"chat_id"`
+	MessageThreadID int64            `json:"message_thread_id,omitempty"`
 	Media           []InputMedia     `json:"media"`
 	ReplyParameters *ReplyParameters `json:"reply_parameters,omitempty"`
 }
@@ -52,6 +121,11 @@ type GetChatMemberParams struct {
 	UserID int64 `json:"user_id"`
 }
 
+// GetFileParams https://core.telegram.org/bots/api#getfile
+type GetFileParams struct {
+	FileID string `json:"file_id"`
+}
+
 // AnswerCallbackQueryParams https://core.telegram.org/bots/api#answercallbackquery
 type AnswerCallbackQueryParams struct {
 	CallbackQueryID string `json:"callback_query_id"`
@@ -60,6 +134,29 @@ type AnswerCallbackQueryParams struct {
 	URL             string `json:"url,omitempty"`
 }
 
+// AnswerInlineQueryParams https://core.telegram.org/bots/api#answerinlinequery
+type AnswerInlineQueryParams struct {
+	InlineQueryID string                    `json:"inline_query_id"`
+	Results       []InlineQueryResult       `json:"results"`
+	CacheTime     int                       `json:"cache_time,omitempty"`
+	IsPersonal    bool                      `json:"is_personal,omitempty"`
+	NextOffset    string                    `json:"next_offset,omitempty"`
+	Button        *InlineQueryResultsButton `json:"button,omitempty"`
+}
+
+// SetMyCommandsParams https://core.telegram.org/bots/api#setmycommands
+type SetMyCommandsParams struct {
+	Commands     []BotCommand     `json:"commands"`
+	Scope        *BotCommandScope `json:"scope,omitempty"`
+	LanguageCode string           `json:"language_code,omitempty"`
+}
+
+// DeleteMyCommandsParams https://core.telegram.org/bots/api#deletemycommands
+type DeleteMyCommandsParams struct {
+	Scope        *BotCommandScope `json:"scope,omitempty"`
+	LanguageCode string           `json:"language_code,omitempty"`
+}
+
 // EditMessageTextParams https://core.telegram.org/bots/api#editmessagetext
 type EditMessageTextParams struct {
 	ChatID             int64               `json:"chat_id"`
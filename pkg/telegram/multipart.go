@@ -14,45 +14,85 @@ import (
 )
 
 func (b *Bot) rawMultipart(ctx context.Context, method string, in any, out any) error {
-	body := &bytes.Buffer{}
-	w := multipart.NewWriter(body)
-
 	m := map[string]any{}
 	fill(m, in)
+	return b.rawForm(ctx, method, m, out)
+}
 
-	if err := makeMultipart(m, w); err != nil {
-		return fmt.Errorf("failed to create muiltipart data: %w", err)
+func (b *Bot) rawForm(ctx context.Context, method string, m map[string]any, out any) error {
+	if b.local {
+		// a local Bot API server reads files right from the disk
+		for key, value := range m {
+			if f, ok := value.(InputFileLocal); ok {
+				if uri, ok := f.uri(); ok {
+					m[key] = uri
+				}
+			}
+		}
 	}
-	w.Close()
 
-	url := b.endpoint + "/bot" + b.token + "/" + method
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
-	if err != nil {
-		return fmt.Errorf("failed to create muiltipart request: %w", err)
+	// files could be re-read from the very same position on retry
+	policy := b.retryPolicy
+	offsets, ok := fileOffsets(m)
+	if !ok {
+		policy.MaxRetries = 0
 	}
-	req.Header.Add("Content-Type", w.FormDataContentType())
 
-	res, err := b.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to perform muiltipart request: %w", err)
+	migrate := func(chatID int64) bool {
+		if _, found := m["chat_id"]; !found || !ok {
+			return false
+		}
+		m["chat_id"] = chatID
+		return true
 	}
-	defer res.Body.Close()
 
-	data, err := io.ReadAll(res.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
+	boundary := multipart.NewWriter(io.Discard).Boundary()
 
-	var r apiResponse
-	if err = json.Unmarshal(data, &r); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
-	}
+	url := b.endpoint + "/bot" + b.token + "/" + method
+	return b.withRetry(ctx, policy, method, func() error {
+		if err := rewind(offsets); err != nil {
+			return fmt.Errorf("failed to rewind files: %w", err)
+		}
+		size, sizeKnown := multipartSize(m, boundary)
 
-	if !r.OK {
-		return fmt.Errorf("telegram error: %s", r.Description)
-	}
+		pr, pw := io.Pipe()
+		w := multipart.NewWriter(pw)
+		if err := w.SetBoundary(boundary); err != nil {
+			return fmt.Errorf("failed to set muiltipart boundary: %w", err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			err := makeMultipart(m, w)
+			if err == nil {
+				err = w.Close()
+			}
+			pw.CloseWithError(err)
+		}()
+		// make sure nobody reads the files when the next attempt rewinds them
+		defer func() {
+			pr.Close()
+			<-done
+		}()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, pr)
+		if err != nil {
+			return fmt.Errorf("failed to create muiltipart request: %w", err)
+		}
+		req.Header.Add("Content-Type", w.FormDataContentType())
+		if sizeKnown {
+			req.ContentLength = size
+		}
 
-	return json.Unmarshal(r.Result, &out)
+		res, err := b.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to perform muiltipart request: %w", err)
+		}
+		defer res.Body.Close()
+
+		return decodeResponse(method, res, out)
+	}, migrate)
 }
 
 func makeMultipart(m map[string]any, w *multipart.Writer) (err error) {
@@ -63,24 +103,19 @@ func makeMultipart(m map[string]any, w *multipart.Writer) (err error) {
 		switch v := value.(type) {
 		case InputFileLocal:
 			reader = v.Reader
+			if v.Progress != nil {
+				total, ok := v.size()
+				if !ok {
+					total = -1
+				}
+				reader = &progressReader{r: v.Reader, total: total, fn: v.Progress}
+			}
 			part, err = w.CreateFormFile(key, v.Name)
-		case string:
-			part, err = w.CreateFormField(key)
-			reader = strings.NewReader(v)
-		case int:
-			part, err = w.CreateFormField(key)
-			reader = strings.NewReader(strconv.Itoa(v))
-		case int64:
-			part, err = w.CreateFormField(key)
-			reader = strings.NewReader(strconv.FormatInt(v, 10))
-		case float64:
-			part, err = w.CreateFormField(key)
-			reader = strings.NewReader(fmt.Sprintf("%.6g", v))
-		case bool, ParseMode:
-			part, err = w.CreateFormField(key)
-			reader = strings.NewReader(fmt.Sprintf("%v", v))
 		default:
-			return fmt.Errorf("unsupported muiltipart/form parameter %v of %T", v, v)
+			if reader, err = fieldReader(v); err != nil {
+				return err
+			}
+			part, err = w.CreateFormField(key)
 		}
 
 		if err != nil {
@@ -95,6 +130,162 @@ func makeMultipart(m map[string]any, w *multipart.Writer) (err error) {
 	return nil
 }
 
+func fieldReader(value any) (io.Reader, error) {
+	switch v := value.(type) {
+	case string:
+		return strings.NewReader(v), nil
+	case InputFileURL:
+		return strings.NewReader(string(v)), nil
+	case int:
+		return strings.NewReader(strconv.Itoa(v)), nil
+	case int64:
+		return strings.NewReader(strconv.FormatInt(v, 10)), nil
+	case float64:
+		return strings.NewReader(fmt.Sprintf("%.6g", v)), nil
+	case bool, ParseMode:
+		return strings.NewReader(fmt.Sprintf("%v", v)), nil
+	default:
+		// objects and arrays are sent as JSON-serialized strings
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported muiltipart/form parameter %v of %T: %w", v, v, err)
+		}
+		return bytes.NewReader(data), nil
+	}
+}
+
+// hasLocalFiles reports whether any of the files has to be uploaded
+func hasLocalFiles(files ...InputFile) bool {
+	for _, f := range files {
+		if _, ok := f.(InputFileLocal); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// attach replaces local files of the media with `attach://<name>` references
+// and returns the files to upload along with them
+func (b *Bot) attach(media []InputMedia) ([]InputMedia, map[string]any) {
+	files := map[string]any{}
+	rv := make([]InputMedia, len(media))
+	for i, item := range media {
+		rv[i] = item.withFiles(func(f InputFile) InputFile {
+			local, ok := f.(InputFileLocal)
+			if !ok {
+				return f
+			}
+			if b.local {
+				if uri, ok := local.uri(); ok {
+					return InputFileURL(uri)
+				}
+			}
+			name := "file" + strconv.Itoa(len(files))
+			files[name] = local
+			return InputFileURL("attach://" + name)
+		})
+	}
+	return rv, files
+}
+
+// multipartSize calculates the body length without reading any file
+func multipartSize(m map[string]any, boundary string) (int64, bool) {
+	var cw countingWriter
+	w := multipart.NewWriter(&cw)
+	if err := w.SetBoundary(boundary); err != nil {
+		return 0, false
+	}
+
+	var files int64
+	fields := map[string]any{}
+	for key, value := range m {
+		f, ok := value.(InputFileLocal)
+		if !ok {
+			fields[key] = value
+			continue
+		}
+
+		size, ok := f.size()
+		if !ok {
+			return 0, false
+		}
+		if _, err := w.CreateFormFile(key, f.Name); err != nil {
+			return 0, false
+		}
+		files += size
+	}
+
+	if err := makeMultipart(fields, w); err != nil {
+		return 0, false
+	}
+	if err := w.Close(); err != nil {
+		return 0, false
+	}
+
+	return cw.n + files, true
+}
+
+type fileOffset struct {
+	s      io.Seeker
+	offset int64
+}
+
+func fileOffsets(m map[string]any) ([]fileOffset, bool) {
+	var offsets []fileOffset
+	for _, value := range m {
+		f, ok := value.(InputFileLocal)
+		if !ok {
+			continue
+		}
+
+		s, ok := f.Reader.(io.Seeker)
+		if !ok {
+			return nil, false
+		}
+
+		offset, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, false
+		}
+		offsets = append(offsets, fileOffset{s: s, offset: offset})
+	}
+	return offsets, true
+}
+
+func rewind(offsets []fileOffset) error {
+	for _, o := range offsets {
+		if _, err := o.s.Seek(o.offset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.n += int64(len(p))
+	return len(p), nil
+}
+
+type progressReader struct {
+	r       io.Reader
+	written int64
+	total   int64
+	fn      func(written, total int64)
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		pr.written += int64(n)
+		pr.fn(pr.written, pr.total)
+	}
+	return n, err
+}
+
 func fill(m map[string]any, in any) {
 	v := reflect.ValueOf(in).Elem()
 	t := v.Type()
@@ -3,6 +3,7 @@ package telegram
 import (
 	"log/slog"
 	"net/http"
+	"strings"
 )
 
 func WithToken(token string) func(*Bot) {
@@ -22,3 +23,29 @@ func WithLogger(logger *slog.Logger) func(*Bot) {
 		b.l = logger
 	}
 }
+
+func WithRetryPolicy(policy RetryPolicy) func(*Bot) {
+	return func(b *Bot) {
+		b.retryPolicy = policy
+	}
+}
+
+func WithRateLimits(limits RateLimits) func(*Bot) {
+	return func(b *Bot) {
+		b.rateLimits = limits
+	}
+}
+
+func WithEndpoint(endpoint string) func(*Bot) {
+	return func(b *Bot) {
+		b.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// WithLocalMode should be used with a self-hosted Bot API server started with `--local`
+// https://github.com/tdlib/telegram-bot-api#usage
+func WithLocalMode(local bool) func(*Bot) {
+	return func(b *Bot) {
+		b.local = local
+	}
+}
@@ -0,0 +1,96 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// RetryPolicy describes how failed requests are repeated.
+// Only flood waits (429) and server errors (5xx) are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of additional attempts, zero disables retries
+	MaxRetries int
+	// MinBackoff is the delay before the first retry of a server error, it doubles with every attempt
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between retries of a server error
+	MaxBackoff time.Duration
+	// MaxRetryAfter is the longest flood wait worth waiting for
+	MaxRetryAfter time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:    3,
+	MinBackoff:    time.Second,
+	MaxBackoff:    30 * time.Second,
+	MaxRetryAfter: 5 * time.Minute,
+}
+
+func (p RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	var e *Error
+	if attempt >= p.MaxRetries || !errors.As(err, &e) {
+		return 0, false
+	}
+
+	if e.TooManyRequests() {
+		return e.RetryAfter, e.RetryAfter <= p.MaxRetryAfter
+	}
+
+	if e.ServerError() {
+		return min(p.MinBackoff<<attempt, p.MaxBackoff), true
+	}
+
+	return 0, false
+}
+
+// withRetry calls fn until it succeeds or the policy gives up. When the group
+// has been upgraded to a supergroup, migrate points the request to the new chat
+// and the request is repeated right away, once.
+func (b *Bot) withRetry(ctx context.Context, policy RetryPolicy, method string, fn func() error, migrate func(chatID int64) bool) error {
+	migrated := false
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		var e *Error
+		if !migrated && migrate != nil && errors.As(err, &e) && e.MigrateToChatID != 0 && migrate(e.MigrateToChatID) {
+			b.l.Warn("chat migrated to supergroup, retrying request", "method", method, "migrate_to_chat_id", e.MigrateToChatID)
+			migrated = true
+			continue
+		}
+
+		delay, ok := policy.delay(attempt, err)
+		if !ok {
+			return err
+		}
+
+		b.l.Warn("retrying request", "method", method, "attempt", attempt+1, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// setChatID replaces the chat of the request parameters
+func setChatID(params any, chatID int64) bool {
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return false
+	}
+
+	f := v.Elem().FieldByName("ChatID")
+	if !f.IsValid() || f.Kind() != reflect.Int64 || !f.CanSet() {
+		return false
+	}
+
+	f.SetInt(chatID)
+	return true
+}
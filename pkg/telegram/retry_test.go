@@ -0,0 +1,41 @@
+package telegram_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/telegramtest"
+)
+
+func TestMigratedChat(t *testing.T) {
+	server := telegramtest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.Fail("sendMessage", &telegram.Error{
+		Code:            http.StatusBadRequest,
+		Description:     "Bad Request: group chat was upgraded to a supergroup chat",
+		MigrateToChatID: -1002,
+	})
+
+	m, err := bot.SendMessage(t.Context(), &telegram.SendMessageParams{ChatID: -1, Text: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Chat.ID != -1002 {
+		t.Errorf("chat id = %d, want -1002", m.Chat.ID)
+	}
+
+	calls := server.Calls("sendMessage")
+	if len(calls) != 2 {
+		t.Fatalf("sendMessage calls = %d, want 2", len(calls))
+	}
+	if chatID := calls[1].Int("chat_id"); chatID != -1002 {
+		t.Errorf("retried with chat id %d, want -1002", chatID)
+	}
+}
@@ -0,0 +1,144 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Rate allows Count calls per Per, up to Count calls could be made at once
+type Rate struct {
+	Count int
+	Per   time.Duration
+}
+
+// RateLimits https://core.telegram.org/bots/faq#my-bot-is-hitting-limits-how-do-i-avoid-this
+type RateLimits struct {
+	Global  Rate
+	Group   Rate
+	Private Rate
+}
+
+var DefaultRateLimits = RateLimits{
+	Global:  Rate{Count: 30, Per: time.Second},
+	Group:   Rate{Count: 20, Per: time.Minute},
+	Private: Rate{Count: 1, Per: time.Second},
+}
+
+func newScheduler(limits RateLimits) *scheduler {
+	return &scheduler{
+		limits: limits,
+		global: newBucket(limits.Global),
+		chats:  map[int64]*bucket{},
+	}
+}
+
+// scheduler paces outgoing calls, so the bot stays within Telegram limits
+// no matter how many handlers are sending messages at the same time
+type scheduler struct {
+	mu     sync.Mutex
+	limits RateLimits
+	global *bucket
+	chats  map[int64]*bucket
+}
+
+// wait blocks until `n` messages could be sent to the chat.
+// Calls for the same chat are served in order of arrival.
+func (s *scheduler) wait(ctx context.Context, chatID int64, n int) error {
+	if chatID != 0 {
+		if err := sleepUntil(ctx, s.reserve(s.chat(chatID), n)); err != nil {
+			return err
+		}
+	}
+	return sleepUntil(ctx, s.reserve(s.global, n))
+}
+
+func (s *scheduler) reserve(b *bucket, n int) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return b.reserve(time.Now(), n)
+}
+
+func (s *scheduler) chat(chatID int64) *bucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.chats[chatID]; ok {
+		return b
+	}
+
+	if len(s.chats) > 1000 {
+		now := time.Now()
+		for id, b := range s.chats {
+			if b.idle(now) {
+				delete(s.chats, id)
+			}
+		}
+	}
+
+	rate := s.limits.Private
+	if chatID < 0 {
+		rate = s.limits.Group
+	}
+	b := newBucket(rate)
+	s.chats[chatID] = b
+	return b
+}
+
+func newBucket(rate Rate) *bucket {
+	if rate.Count <= 0 || rate.Per <= 0 {
+		return &bucket{}
+	}
+	interval := rate.Per / time.Duration(rate.Count)
+	return &bucket{
+		interval: interval,
+		window:   interval * time.Duration(rate.Count-1),
+	}
+}
+
+// bucket is a GCRA flavour of token bucket
+type bucket struct {
+	interval time.Duration
+	window   time.Duration
+	tat      time.Time // theoretical arrival time of the next call
+}
+
+func (b *bucket) reserve(now time.Time, n int) time.Time {
+	if b.interval == 0 {
+		return now // unlimited
+	}
+
+	tat := b.tat
+	if tat.Before(now) {
+		tat = now
+	}
+
+	at := tat.Add(-b.window)
+	if at.Before(now) {
+		at = now
+	}
+
+	b.tat = tat.Add(b.interval * time.Duration(max(n, 1)))
+	return at
+}
+
+func (b *bucket) idle(now time.Time) bool {
+	return b.tat.Before(now)
+}
+
+func sleepUntil(ctx context.Context, at time.Time) error {
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
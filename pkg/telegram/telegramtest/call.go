@@ -0,0 +1,54 @@
+package telegramtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Call is a recorded Bot API method call
+type Call struct {
+	Method string
+	// Params are decoded JSON values, or strings for multipart/form-data requests
+	Params map[string]any
+	// Files are multipart/form-data uploads keyed by field name
+	Files map[string]File
+}
+
+type File struct {
+	Name string
+	Data []byte
+}
+
+func (c Call) String(key string) string {
+	switch v := c.Params[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+func (c Call) Int(key string) int64 {
+	switch v := c.Params[key].(type) {
+	case float64:
+		return int64(v)
+	case string:
+		i, _ := strconv.ParseInt(v, 10, 64)
+		return i
+	default:
+		return 0
+	}
+}
+
+// Decode unpacks a JSON parameter such as `reply_markup` or `media`
+func (c Call) Decode(key string, v any) error {
+	data := []byte(c.String(key))
+	if len(data) == 0 {
+		return fmt.Errorf("parameter %s not found", key)
+	}
+	return json.Unmarshal(data, v)
+}
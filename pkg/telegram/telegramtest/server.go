@@ -0,0 +1,388 @@
+// Package telegramtest provides an in-process fake of the Telegram Bot API
+package telegramtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+const Token = "123456:TEST-TOKEN"
+
+func NewServer() *Server {
+	s := &Server{
+		Me: &telegram.User{
+			ID:        123456,
+			FirstName: "Test",
+			Username:  "test_bot",
+			IsBot:     true,
+		},
+		errors:   map[string][]*telegram.Error{},
+		replies:  map[string]ReplyFunc{},
+		files:    map[string][]byte{},
+		notify:   make(chan struct{}),
+		updateID: 1,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// ReplyFunc returns the `result` of a method call
+type ReplyFunc func(call Call) any
+
+// Server records every method call and answers with plausible results
+type Server struct {
+	*httptest.Server
+	Me *telegram.User
+
+	mu        sync.Mutex
+	calls     []Call
+	updates   []*telegram.Update
+	errors    map[string][]*telegram.Error
+	replies   map[string]ReplyFunc
+	files     map[string][]byte
+	notify    chan struct{}
+	messageID int64
+	updateID  int64
+}
+
+// Options configure a bot to talk to the server without delays
+func (s *Server) Options() []func(*telegram.Bot) {
+	return []func(*telegram.Bot){
+		telegram.WithToken(Token),
+		telegram.WithEndpoint(s.URL),
+		telegram.WithClient(s.Client()),
+		telegram.WithRetryPolicy(telegram.RetryPolicy{}),
+		telegram.WithRateLimits(telegram.RateLimits{}),
+	}
+}
+
+func (s *Server) NewBot(ctx context.Context, opts ...func(*telegram.Bot)) (*telegram.Bot, error) {
+	return telegram.NewBot(ctx, append(s.Options(), opts...)...)
+}
+
+// AddUpdate queues the update for getUpdates, an empty ID is assigned automatically
+func (s *Server) AddUpdate(u *telegram.Update) *telegram.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u.ID == 0 {
+		u.ID = s.updateID
+	}
+	s.updateID = max(s.updateID, u.ID+1)
+	s.updates = append(s.updates, u)
+
+	close(s.notify)
+	s.notify = make(chan struct{})
+	return u
+}
+
+// Fail makes the next call of the method return the error
+func (s *Server) Fail(method string, err *telegram.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[method] = append(s.errors[method], err)
+}
+
+// Reply overrides the result of the method
+func (s *Server) Reply(method string, fn ReplyFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies[method] = fn
+}
+
+// AddFile makes the file available through getFile and the file download endpoint
+func (s *Server) AddFile(fileID string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[fileID] = data
+}
+
+// Calls returns recorded calls of the methods, or all of them when none specified
+func (s *Server) Calls(methods ...string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []Call
+	for _, c := range s.calls {
+		if len(methods) == 0 || slices.Contains(methods, c.Method) {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets recorded calls, scripted errors and replies
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+	s.errors = map[string][]*telegram.Error{}
+	s.replies = map[string]ReplyFunc{}
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if path, ok := strings.CutPrefix(r.URL.Path, "/file/bot"+Token+"/"); ok {
+		s.serveFile(w, path)
+		return
+	}
+
+	method, ok := strings.CutPrefix(r.URL.Path, "/bot"+Token+"/")
+	if !ok {
+		writeError(w, &telegram.Error{Code: http.StatusUnauthorized, Description: "Unauthorized"})
+		return
+	}
+
+	call, err := parseCall(method, r)
+	if err != nil {
+		writeError(w, &telegram.Error{Code: http.StatusBadRequest, Description: "Bad Request: " + err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	if errs := s.errors[method]; len(errs) > 0 {
+		s.errors[method] = errs[1:]
+		s.mu.Unlock()
+		writeError(w, errs[0])
+		return
+	}
+	reply, ok := s.replies[method]
+	s.mu.Unlock()
+
+	var result any
+	if ok {
+		result = reply(call)
+	} else {
+		result = s.defaultResult(r.Context(), call)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, path string) {
+	s.mu.Lock()
+	data, ok := s.files[strings.TrimPrefix(path, "files/")]
+	s.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+func (s *Server) defaultResult(ctx context.Context, call Call) any {
+	switch call.Method {
+	case "getMe":
+		return s.Me
+	case "getUpdates":
+		return s.getUpdates(ctx, call)
+	case "getWebhookInfo":
+		return &telegram.WebhookInfo{}
+	case "getChatMember":
+		return &telegram.ChatMember{
+			Status: "member",
+			User:   &telegram.User{ID: call.Int("user_id")},
+		}
+	case "getFile":
+		fileID := call.String("file_id")
+		s.mu.Lock()
+		data, ok := s.files[fileID]
+		s.mu.Unlock()
+		if !ok {
+			return &telegram.File{FileID: fileID}
+		}
+		return &telegram.File{
+			FileID:   fileID,
+			FileSize: int64(len(data)),
+			FilePath: "files/" + fileID,
+		}
+	case "sendMediaGroup":
+		var media []map[string]any
+		_ = call.Decode("media", &media)
+		messages := []*telegram.Message{}
+		for _, item := range media {
+			kind, _ := item["type"].(string)
+			messages = append(messages, s.message(call, kind))
+		}
+		return messages
+	case "sendMessage", "editMessageText":
+		return s.message(call, "text")
+	case "sendPhoto":
+		return s.message(call, "photo")
+	case "sendVideo":
+		return s.message(call, "video")
+	case "sendAnimation":
+		return s.message(call, "animation")
+	case "sendAudio":
+		return s.message(call, "audio")
+	case "sendDocument":
+		return s.message(call, "document")
+	case "sendVoice":
+		return s.message(call, "voice")
+	default:
+		if strings.HasPrefix(call.Method, "send") {
+			return s.message(call, "")
+		}
+		return true
+	}
+}
+
+func (s *Server) getUpdates(ctx context.Context, call Call) []*telegram.Update {
+	offset := call.Int("offset")
+	timeout := time.Duration(call.Int("timeout")) * time.Second
+	deadline := time.After(timeout)
+
+	for {
+		s.mu.Lock()
+		updates := []*telegram.Update{}
+		pending := s.updates[:0]
+		for _, u := range s.updates {
+			if u.ID >= offset {
+				updates = append(updates, u)
+				pending = append(pending, u)
+			}
+		}
+		// updates before the offset are confirmed
+		s.updates = pending
+		notify := s.notify
+		s.mu.Unlock()
+
+		if limit := int(call.Int("limit")); limit > 0 && len(updates) > limit {
+			updates = updates[:limit]
+		}
+		if len(updates) > 0 || timeout == 0 {
+			return updates
+		}
+
+		select {
+		case <-notify:
+		case <-deadline:
+			return updates
+		case <-ctx.Done():
+			return updates
+		}
+	}
+}
+
+func (s *Server) message(call Call, kind string) *telegram.Message {
+	s.mu.Lock()
+	s.messageID++
+	id := s.messageID
+	s.mu.Unlock()
+
+	if messageID := call.Int("message_id"); call.Method == "editMessageText" && messageID != 0 {
+		id = messageID
+	}
+
+	m := &telegram.Message{
+		ID:      id,
+		From:    s.Me,
+		Date:    int(time.Now().Unix()),
+		Chat:    &telegram.Chat{ID: call.Int("chat_id"), Type: "private"},
+		Text:    call.String("text"),
+		Caption: call.String("caption"),
+	}
+	if m.Chat.ID < 0 {
+		m.Chat.Type = "supergroup"
+	}
+	if threadID := call.Int("message_thread_id"); threadID != 0 {
+		m.MessageThreadID = threadID
+		m.IsTopicMessage = true
+	}
+
+	fileID := fmt.Sprintf("%s-%d", kind, id)
+	switch kind {
+	case "photo":
+		m.Photo = []*telegram.PhotoSize{{FileID: fileID, FileUniqueID: fileID}}
+	case "video":
+		m.Video = &telegram.Video{FileID: fileID, FileUniqueID: fileID}
+	case "animation":
+		m.Animation = &telegram.Animation{FileID: fileID, FileUniqueID: fileID}
+	case "audio":
+		m.Audio = &telegram.Audio{FileID: fileID, FileUniqueID: fileID}
+	case "document":
+		m.Document = &telegram.Document{FileID: fileID, FileUniqueID: fileID}
+	case "voice":
+		m.Voice = &telegram.Voice{FileID: fileID, FileUniqueID: fileID}
+	}
+	return m
+}
+
+func parseCall(method string, r *http.Request) (Call, error) {
+	call := Call{
+		Method: method,
+		Params: map[string]any{},
+		Files:  map[string]File{},
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return call, err
+		}
+		for key, values := range r.MultipartForm.Value {
+			call.Params[key] = values[0]
+		}
+		for key, headers := range r.MultipartForm.File {
+			f, err := headers[0].Open()
+			if err != nil {
+				return call, err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return call, err
+			}
+			call.Files[key] = File{Name: headers[0].Filename, Data: data}
+		}
+	default:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return call, err
+		}
+		if len(strings.TrimSpace(string(data))) > 0 && string(data) != "null\n" {
+			if err := json.Unmarshal(data, &call.Params); err != nil {
+				return call, err
+			}
+		}
+	}
+
+	return call, nil
+}
+
+func writeError(w http.ResponseWriter, e *telegram.Error) {
+	response := map[string]any{
+		"ok":          false,
+		"error_code":  e.Code,
+		"description": e.Description,
+	}
+
+	params := map[string]any{}
+	if e.RetryAfter > 0 {
+		params["retry_after"] = int(e.RetryAfter.Seconds())
+	}
+	if e.MigrateToChatID != 0 {
+		params["migrate_to_chat_id"] = e.MigrateToChatID
+	}
+	if len(params) > 0 {
+		response["parameters"] = params
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Code)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,25 @@
+package telegram
+
+import "context"
+
+type topicKey struct{}
+
+type topic struct {
+	chatID   int64
+	threadID int64
+}
+
+// WithTopic makes the messages sent with the context to the chat of `m`
+// go to the same forum topic, unless the message thread is set explicitly
+func WithTopic(ctx context.Context, m *Message) context.Context {
+	if m == nil || !m.IsTopicMessage || m.Chat == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, topicKey{}, topic{chatID: m.Chat.ID, threadID: m.MessageThreadID})
+}
+
+func inTopic(ctx context.Context, chatID int64, threadID *int64) {
+	if t, ok := ctx.Value(topicKey{}).(topic); ok && *threadID == 0 && t.chatID == chatID {
+		*threadID = t.threadID
+	}
+}
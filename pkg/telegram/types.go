@@ -1,12 +1,38 @@
 package telegram
 
-import "io"
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+)
 
 // Update https://core.telegram.org/bots/api#update
 type Update struct {
-	ID            int64          `json:"update_id"`
-	Message       *Message       `json:"message,omitempty"`
-	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+	ID                 int64                   `json:"update_id"`
+	Message            *Message                `json:"message,omitempty"`
+	InlineQuery        *InlineQuery            `json:"inline_query,omitempty"`
+	ChosenInlineResult *ChosenInlineResult     `json:"chosen_inline_result,omitempty"`
+	CallbackQuery      *CallbackQuery          `json:"callback_query,omitempty"`
+	EditedMessage      *Message                `json:"edited_message,omitempty"`
+	ChannelPost        *Message                `json:"channel_post,omitempty"`
+	EditedChannelPost  *Message                `json:"edited_channel_post,omitempty"`
+	MyChatMember       *ChatMemberUpdated      `json:"my_chat_member,omitempty"`
+	ChatMember         *ChatMemberUpdated      `json:"chat_member,omitempty"`
+	MessageReaction    *MessageReactionUpdated `json:"message_reaction,omitempty"`
+}
+
+// WebhookInfo https://core.telegram.org/bots/api#webhookinfo
+type WebhookInfo struct {
+	URL                          string   `json:"url"`
+	HasCustomCertificate         bool     `json:"has_custom_certificate"`
+	PendingUpdateCount           int      `json:"pending_update_count"`
+	IPAddress                    string   `json:"ip_address,omitempty"`
+	LastErrorDate                int      `json:"last_error_date,omitempty"`
+	LastErrorMessage             string   `json:"last_error_message,omitempty"`
+	LastSynchronizationErrorDate int      `json:"last_synchronization_error_date,omitempty"`
+	MaxConnections               int      `json:"max_connections,omitempty"`
+	AllowedUpdates               []string `json:"allowed_updates,omitempty"`
 }
 
 // User https://core.telegram.org/bots/api#user
@@ -40,6 +66,7 @@ type Chat struct {
 	Username  string `json:"username,omitempty"`
 	FirstName string `json:"first_name,omitempty"`
 	LastName  string `json:"last_name,omitempty"`
+	IsForum   bool   `json:"is_forum,omitempty"`
 }
 
 func (c *Chat) Private() bool {
@@ -49,7 +76,10 @@ func (c *Chat) Private() bool {
 // Message https://core.telegram.org/bots/api#message
 type Message struct {
 	ID                int64           `json:"message_id"`
+	MessageThreadID   int64           `json:"message_thread_id,omitempty"`
+	IsTopicMessage    bool            `json:"is_topic_message,omitempty"`
 	From              *User           `json:"from,omitempty"`
+	SenderChat        *Chat           `json:"sender_chat,omitempty"`
 	Date              int             `json:"date"`
 	Chat              *Chat           `json:"chat"`
 	ForwardFrom       *User           `json:"forward_from,omitempty"`
@@ -60,26 +90,62 @@ type Message struct {
 	Entities          []MessageEntity `json:"entities,omitempty"`
 	Photo             []*PhotoSize    `json:"photo,omitempty"`
 	Video             *Video          `json:"video,omitempty"`
+	Animation         *Animation      `json:"animation,omitempty"`
+	Audio             *Audio          `json:"audio,omitempty"`
+	Document          *Document       `json:"document,omitempty"`
+	Voice             *Voice          `json:"voice,omitempty"`
 	Caption           string          `json:"caption,omitempty"`
+	CaptionEntities   []MessageEntity `json:"caption_entities,omitempty"`
+	MigrateToChatID   int64           `json:"migrate_to_chat_id,omitempty"`
+	MigrateFromChatID int64           `json:"migrate_from_chat_id,omitempty"`
 }
 
 func (m *Message) Commands() []string {
-	return m.entities("bot_command")
+	var commands = []string{}
+	m.eachEntity(func(text string, e MessageEntity) {
+		if e.Type == "bot_command" {
+			commands = append(commands, text)
+		}
+	})
+	return commands
 }
 
+// URLs returns both plain links and the targets of `text_link` entities
 func (m *Message) URLs() []string {
-	return m.entities("url")
+	var urls = []string{}
+	m.eachEntity(func(text string, e MessageEntity) {
+		switch e.Type {
+		case "url":
+			urls = append(urls, text)
+		case "text_link":
+			urls = append(urls, e.URL)
+		}
+	})
+	return urls
 }
 
-func (m *Message) entities(kind string) []string {
-	var urls = []string{}
-	runes := []rune(m.Text)
-	for _, e := range m.Entities {
-		if e.Type == kind {
-			urls = append(urls, string(runes[e.Offset:e.Offset+e.Length]))
+// eachEntity walks through entities of both text and caption
+func (m *Message) eachEntity(fn func(text string, e MessageEntity)) {
+	for _, source := range []struct {
+		text     string
+		entities []MessageEntity
+	}{
+		{m.Text, m.Entities},
+		{m.Caption, m.CaptionEntities},
+	} {
+		if len(source.entities) == 0 {
+			continue
+		}
+
+		// entity offsets and lengths are measured in UTF-16 code units
+		units := utf16.Encode([]rune(source.text))
+		for _, e := range source.entities {
+			if e.Offset < 0 || e.Length < 0 || e.Offset+e.Length > len(units) {
+				continue
+			}
+			fn(string(utf16.Decode(units[e.Offset:e.Offset+e.Length])), e)
 		}
 	}
-	return urls
 }
 
 // ParseMode https://core.telegram.org/bots/api#formatting-options
@@ -92,9 +158,13 @@ const (
 
 // MessageEntity https://core.telegram.org/bots/api#messageentity
 type MessageEntity struct {
-	Type   string `json:"type"`
-	Offset int    `json:"offset"`
-	Length int    `json:"length"`
+	Type          string `json:"type"`
+	Offset        int    `json:"offset"`
+	Length        int    `json:"length"`
+	URL           string `json:"url,omitempty"`
+	User          *User  `json:"user,omitempty"`
+	Language      string `json:"language,omitempty"`
+	CustomEmojiID string `json:"custom_emoji_id,omitempty"`
 }
 
 // PhotoSize https://core.telegram.org/bots/api#photosize
@@ -121,6 +191,59 @@ type Video struct {
 	FileSize       int64        `json:"file_size"`
 }
 
+// Animation https://core.telegram.org/bots/api#animation
+type Animation struct {
+	FileID       string     `json:"file_id"`
+	FileUniqueID string     `json:"file_unique_id"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	Duration     int        `json:"duration"`
+	Thumbnail    *PhotoSize `json:"thumbnail,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size"`
+}
+
+// Audio https://core.telegram.org/bots/api#audio
+type Audio struct {
+	FileID       string     `json:"file_id"`
+	FileUniqueID string     `json:"file_unique_id"`
+	Duration     int        `json:"duration"`
+	Performer    string     `json:"performer,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size"`
+	Thumbnail    *PhotoSize `json:"thumbnail,omitempty"`
+}
+
+// Document https://core.telegram.org/bots/api#document
+type Document struct {
+	FileID       string     `json:"file_id"`
+	FileUniqueID string     `json:"file_unique_id"`
+	Thumbnail    *PhotoSize `json:"thumbnail,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size"`
+}
+
+// Voice https://core.telegram.org/bots/api#voice
+type Voice struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Duration     int    `json:"duration"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size"`
+}
+
+// File https://core.telegram.org/bots/api#file
+type File struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	FileSize     int64  `json:"file_size,omitempty"`
+	FilePath     string `json:"file_path,omitempty"`
+}
+
 // ReplyParameters https://core.telegram.org/bots/api#replyparameters
 type ReplyParameters struct {
 	MessageID int64  `json:"message_id"`
@@ -144,22 +267,114 @@ type CallbackQuery struct {
 	Data         string `json:"data,omitempty"`
 }
 
-// ReplyMarkup
-type ReplyMarkup interface {
-	isReplyMarkup() // marker interface
+// InlineQuery https://core.telegram.org/bots/api#inlinequery
+type InlineQuery struct {
+	ID       string `json:"id"`
+	From     *User  `json:"from"`
+	Query    string `json:"query"`
+	Offset   string `json:"offset"`
+	ChatType string `json:"chat_type,omitempty"`
+}
+
+// ChosenInlineResult https://core.telegram.org/bots/api#choseninlineresult
+type ChosenInlineResult struct {
+	ResultID        string `json:"result_id"`
+	From            *User  `json:"from"`
+	InlineMessageID string `json:"inline_message_id,omitempty"`
+	Query           string `json:"query"`
+}
+
+// InlineQueryResult https://core.telegram.org/bots/api#inlinequeryresult
+type InlineQueryResult interface {
+	isInlineQueryResult() // marker interface
+}
+
+// InlineQueryResultArticle https://core.telegram.org/bots/api#inlinequeryresultarticle
+type InlineQueryResultArticle struct {
+	Type                string                `json:"type"`
+	ID                  string                `json:"id"`
+	Title               string                `json:"title"`
+	InputMessageContent InputMessageContent   `json:"input_message_content"`
+	ReplyMarkup         *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	URL                 string                `json:"url,omitempty"`
+	Description         string                `json:"description,omitempty"`
+	ThumbnailURL        string                `json:"thumbnail_url,omitempty"`
+}
+
+func (InlineQueryResultArticle) isInlineQueryResult() {}
+
+// InlineQueryResultCachedPhoto https://core.telegram.org/bots/api#inlinequeryresultcachedphoto
+type InlineQueryResultCachedPhoto struct {
+	Type                  string                `json:"type"`
+	ID                    string                `json:"id"`
+	PhotoFileID           string                `json:"photo_file_id"`
+	Title                 string                `json:"title,omitempty"`
+	Description           string                `json:"description,omitempty"`
+	Caption               string                `json:"caption,omitempty"`
+	ParseMode             ParseMode             `json:"parse_mode,omitempty"`
+	ShowCaptionAboveMedia bool                  `json:"show_caption_above_media,omitempty"`
+	ReplyMarkup           *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+func (InlineQueryResultCachedPhoto) isInlineQueryResult() {}
+
+// InlineQueryResultCachedVideo https://core.telegram.org/bots/api#inlinequeryresultcachedvideo
+type InlineQueryResultCachedVideo struct {
+	Type                  string                `json:"type"`
+	ID                    string                `json:"id"`
+	VideoFileID           string                `json:"video_file_id"`
+	Title                 string                `json:"title"`
+	Description           string                `json:"description,omitempty"`
+	Caption               string                `json:"caption,omitempty"`
+	ParseMode             ParseMode             `json:"parse_mode,omitempty"`
+	ShowCaptionAboveMedia bool                  `json:"show_caption_above_media,omitempty"`
+	ReplyMarkup           *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+func (InlineQueryResultCachedVideo) isInlineQueryResult() {}
+
+// InlineQueryResultsButton https://core.telegram.org/bots/api#inlinequeryresultsbutton
+type InlineQueryResultsButton struct {
+	Text           string `json:"text"`
+	StartParameter string `json:"start_parameter,omitempty"`
+}
+
+// InputMessageContent https://core.telegram.org/bots/api#inputmessagecontent
+type InputMessageContent interface {
+	isInputMessageContent() // marker interface
+}
+
+// InputTextMessageContent https://core.telegram.org/bots/api#inputtextmessagecontent
+type InputTextMessageContent struct {
+	MessageText        string              `json:"message_text"`
+	ParseMode          ParseMode           `json:"parse_mode,omitempty"`
+	LinkPreviewOptions *LinkPreviewOptions `json:"link_preview_options,omitempty"`
 }
 
-type InlineKeyboardMarkup struct {
-	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+func (InputTextMessageContent) isInputMessageContent() {}
+
+// BotCommand https://core.telegram.org/bots/api#botcommand
+type BotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
 }
 
-type InlineKeyboardButton struct {
-	Text         string `json:"text"`
-	CallbackData string `json:"callback_data,omitempty"`
-	URL          string `json:"url,omitempty"`
+// BotCommandScope https://core.telegram.org/bots/api#botcommandscope
+type BotCommandScope struct {
+	Type   string `json:"type"`
+	ChatID int64  `json:"chat_id,omitempty"`
+	UserID int64  `json:"user_id,omitempty"`
 }
 
-func (InlineKeyboardMarkup) isReplyMarkup() {}
+const (
+	BotCommandScopeDefault               = "default"
+	BotCommandScopeAllPrivateChats       = "all_private_chats"
+	BotCommandScopeAllGroupChats         = "all_group_chats"
+	BotCommandScopeAllChatAdministrators = "all_chat_administrators"
+	BotCommandScopeChat                  = "chat"
+	BotCommandScopeChatAdministrators    = "chat_administrators"
+	BotCommandScopeChatMember            = "chat_member"
+)
 
 // ChatMember https://core.telegram.org/bots/api#chatmember
 type ChatMember struct {
@@ -167,6 +382,50 @@ type ChatMember struct {
 	User   *User  `json:"user"`
 }
 
+// Administrator reports whether the member can manage the chat
+func (m *ChatMember) Administrator() bool {
+	return m.Status == "creator" || m.Status == "administrator"
+}
+
+// Present reports whether the member is in the chat
+func (m *ChatMember) Present() bool {
+	switch m.Status {
+	case "left", "kicked":
+		return false
+	default:
+		return true
+	}
+}
+
+// ChatMemberUpdated https://core.telegram.org/bots/api#chatmemberupdated
+type ChatMemberUpdated struct {
+	Chat                    *Chat       `json:"chat"`
+	From                    *User       `json:"from"`
+	Date                    int         `json:"date"`
+	OldChatMember           *ChatMember `json:"old_chat_member"`
+	NewChatMember           *ChatMember `json:"new_chat_member"`
+	ViaJoinRequest          bool        `json:"via_join_request,omitempty"`
+	ViaChatFolderInviteLink bool        `json:"via_chat_folder_invite_link,omitempty"`
+}
+
+// MessageReactionUpdated https://core.telegram.org/bots/api#messagereactionupdated
+type MessageReactionUpdated struct {
+	Chat        *Chat          `json:"chat"`
+	MessageID   int64          `json:"message_id"`
+	User        *User          `json:"user,omitempty"`
+	ActorChat   *Chat          `json:"actor_chat,omitempty"`
+	Date        int            `json:"date"`
+	OldReaction []ReactionType `json:"old_reaction"`
+	NewReaction []ReactionType `json:"new_reaction"`
+}
+
+// ReactionType https://core.telegram.org/bots/api#reactiontype
+type ReactionType struct {
+	Type          string `json:"type"`
+	Emoji         string `json:"emoji,omitempty"`
+	CustomEmojiID string `json:"custom_emoji_id,omitempty"`
+}
+
 // InputFile https://core.telegram.org/bots/api#inputfile
 type InputFile interface {
 	isInputFile() // marker interface
@@ -179,19 +438,55 @@ func (InputFileURL) isInputFile() {}
 type InputFileLocal struct {
 	Name   string
 	Reader io.Reader
+	// Progress is called from the uploading goroutine, total is -1 when unknown
+	Progress func(written, total int64)
 }
 
 func (InputFileLocal) isInputFile() {}
 
+func (f InputFileLocal) uri() (string, bool) {
+	file, ok := f.Reader.(*os.File)
+	if !ok {
+		return "", false
+	}
+
+	path, err := filepath.Abs(file.Name())
+	if err != nil {
+		return "", false
+	}
+	return "file://" + path, true
+}
+
+func (f InputFileLocal) size() (int64, bool) {
+	switch r := f.Reader.(type) {
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		return info.Size() - offset, true
+	case interface{ Len() int }:
+		return int64(r.Len()), true
+	default:
+		return 0, false
+	}
+}
+
 // InputMedia https://core.telegram.org/bots/api#inputmedia
 type InputMedia interface {
 	isInputMedia() // marker interface
+	// withFiles returns a copy with files replaced by `fn`
+	withFiles(fn func(InputFile) InputFile) InputMedia
 }
 
 // InputMediaPhoto https://core.telegram.org/bots/api#inputmediaphoto
 type InputMediaPhoto struct {
 	Type                  string    `json:"type"`
-	Media                 string    `json:"media"`
+	Media                 InputFile `json:"media"`
 	Caption               string    `json:"caption,omitempty"`
 	ParseMode             ParseMode `json:"parse_mode,omitempty"`
 	ShowCaptionAboveMedia bool      `json:"show_caption_above_media,omitempty"`
@@ -200,11 +495,16 @@ type InputMediaPhoto struct {
 
 func (InputMediaPhoto) isInputMedia() {}
 
+func (m InputMediaPhoto) withFiles(fn func(InputFile) InputFile) InputMedia {
+	m.Media = fn(m.Media)
+	return m
+}
+
 // InputMediaVideo https://core.telegram.org/bots/api#inputmediavideo
 type InputMediaVideo struct {
 	Type                  string    `json:"type"`
-	Media                 string    `json:"media"`
-	Thumbnail             string    `json:"thumbnail"`
+	Media                 InputFile `json:"media"`
+	Thumbnail             InputFile `json:"thumbnail,omitempty"`
 	Caption               string    `json:"caption,omitempty"`
 	ParseMode             ParseMode `json:"parse_mode,omitempty"`
 	ShowCaptionAboveMedia bool      `json:"show_caption_above_media,omitempty"`
@@ -216,3 +516,53 @@ type InputMediaVideo struct {
 }
 
 func (InputMediaVideo) isInputMedia() {}
+
+func (m InputMediaVideo) withFiles(fn func(InputFile) InputFile) InputMedia {
+	m.Media = fn(m.Media)
+	if m.Thumbnail != nil {
+		m.Thumbnail = fn(m.Thumbnail)
+	}
+	return m
+}
+
+// InputMediaAudio https://core.telegram.org/bots/api#inputmediaaudio
+type InputMediaAudio struct {
+	Type      string    `json:"type"`
+	Media     InputFile `json:"media"`
+	Thumbnail InputFile `json:"thumbnail,omitempty"`
+	Caption   string    `json:"caption,omitempty"`
+	ParseMode ParseMode `json:"parse_mode,omitempty"`
+	Duration  int       `json:"duration,omitempty"`
+	Performer string    `json:"performer,omitempty"`
+	Title     string    `json:"title,omitempty"`
+}
+
+func (InputMediaAudio) isInputMedia() {}
+
+func (m InputMediaAudio) withFiles(fn func(InputFile) InputFile) InputMedia {
+	m.Media = fn(m.Media)
+	if m.Thumbnail != nil {
+		m.Thumbnail = fn(m.Thumbnail)
+	}
+	return m
+}
+
+// InputMediaDocument https://core.telegram.org/bots/api#inputmediadocument
+type InputMediaDocument struct {
+	Type                        string    `json:"type"`
+	Media                       InputFile `json:"media"`
+	Thumbnail                   InputFile `json:"thumbnail,omitempty"`
+	Caption                     string    `json:"caption,omitempty"`
+	ParseMode                   ParseMode `json:"parse_mode,omitempty"`
+	DisableContentTypeDetection bool      `json:"disable_content_type_detection,omitempty"`
+}
+
+func (InputMediaDocument) isInputMedia() {}
+
+func (m InputMediaDocument) withFiles(fn func(InputFile) InputFile) InputMedia {
+	m.Media = fn(m.Media)
+	if m.Thumbnail != nil {
+		m.Thumbnail = fn(m.Thumbnail)
+	}
+	return m
+}
@@ -0,0 +1,67 @@
+package telegram_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+)
+
+func TestMessageURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *telegram.Message
+		urls    []string
+	}{
+		{
+			name: "emoji before url",
+			message: &telegram.Message{
+				Text:     "😀 look https://example.com/a",
+				Entities: []telegram.MessageEntity{{Type: "url", Offset: 8, Length: 21}},
+			},
+			urls: []string{"https://example.com/a"},
+		},
+		{
+			name: "text link",
+			message: &telegram.Message{
+				Text:     "🎞 video",
+				Entities: []telegram.MessageEntity{{Type: "text_link", Offset: 3, Length: 5, URL: "https://example.com/v"}},
+			},
+			urls: []string{"https://example.com/v"},
+		},
+		{
+			name: "caption",
+			message: &telegram.Message{
+				Caption:         "👍👍 https://example.com/c",
+				CaptionEntities: []telegram.MessageEntity{{Type: "url", Offset: 5, Length: 21}},
+			},
+			urls: []string{"https://example.com/c"},
+		},
+		{
+			name: "out of range",
+			message: &telegram.Message{
+				Text:     "https://example.com",
+				Entities: []telegram.MessageEntity{{Type: "url", Offset: 10, Length: 20}},
+			},
+			urls: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if urls := tt.message.URLs(); !slices.Equal(urls, tt.urls) {
+				t.Errorf("URLs() = %q, want %q", urls, tt.urls)
+			}
+		})
+	}
+}
+
+func TestMessageCommands(t *testing.T) {
+	m := &telegram.Message{
+		Text:     "😀 /info@test_bot",
+		Entities: []telegram.MessageEntity{{Type: "bot_command", Offset: 3, Length: 14}},
+	}
+	if commands := m.Commands(); !slices.Equal(commands, []string{"/info@test_bot"}) {
+		t.Errorf("Commands() = %q", commands)
+	}
+}
@@ -0,0 +1,49 @@
+package telegram
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+// maxWebhookBodySize is way above any update Telegram sends
+const maxWebhookBodySize = 1 << 20
+
+// WebhookHandler returns an http.Handler receiving updates pushed by Telegram
+// https://core.telegram.org/bots/api#setwebhook
+//
+// Every request must carry the same `X-Telegram-Bot-Api-Secret-Token` header
+// that was passed to SetWebhook as `secret_token`, the token is required.
+// Decoded updates are sent to the channel, so the request is acknowledged
+// as soon as someone reads it.
+func (b *Bot) WebhookHandler(secretToken string, updates chan<- *Update) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if secretToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secretToken)) != 1 {
+			b.l.Warn("webhook secret token mismatch", "remote_addr", r.RemoteAddr)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var update Update
+		body := http.MaxBytesReader(w, r.Body, maxWebhookBodySize)
+		if err := json.NewDecoder(body).Decode(&update); err != nil {
+			b.l.Error("failed to decode webhook update", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case updates <- &update:
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+			// Telegram will redeliver the update later
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+}
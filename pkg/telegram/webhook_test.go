@@ -0,0 +1,53 @@
+package telegram_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ailinykh/reposter/v3/pkg/telegram"
+	"github.com/ailinykh/reposter/v3/pkg/telegram/telegramtest"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	server := telegramtest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		token  string
+		body   string
+		status int
+	}{
+		{"valid", "secret", "secret", `{"update_id":1}`, http.StatusOK},
+		{"wrong token", "secret", "wrong", `{"update_id":1}`, http.StatusUnauthorized},
+		{"no secret", "", "", `{"update_id":1}`, http.StatusUnauthorized},
+		{"malformed", "secret", "secret", `{`, http.StatusBadRequest},
+		{"too large", "secret", "secret", `{"update_id":1,"x":"` + strings.Repeat("x", 2<<20) + `"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := make(chan *telegram.Update, 1)
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("X-Telegram-Bot-Api-Secret-Token", tt.token)
+			rec := httptest.NewRecorder()
+
+			bot.WebhookHandler(tt.secret, updates).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := len(updates); (tt.status == http.StatusOK) != (got == 1) {
+				t.Errorf("updates received = %d", got)
+			}
+		})
+	}
+}
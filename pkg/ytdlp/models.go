@@ -29,6 +29,12 @@ type Response struct {
 	MediaType   string    `json:"media_type"`
 	OriginalUrl string    `json:"original_url"`
 	WebpageUrl  string    `json:"webpage_url"`
+	// PlaylistID identifies the multi-item post the item belongs to
+	PlaylistID string `json:"playlist_id"`
+	// PlaylistIndex is the 1-based position of the item within a multi-item post
+	PlaylistIndex int `json:"playlist_index"`
+	// Entries holds every item of a multi-item post, including the first one
+	Entries []*Response `json:"-"`
 }
 
 func (r *Response) FormatByID(id string) (*Format, error) {
@@ -8,6 +8,13 @@ func WithArgs(args []string) func(*YtDlp) {
 	}
 }
 
+// WithTempDir sets the directory the download directories are created in
+func WithTempDir(dir string) func(*YtDlp) {
+	return func(yd *YtDlp) {
+		yd.tempDir = dir
+	}
+}
+
 func WithLogger(l *slog.Logger) func(*YtDlp) {
 	return func(yd *YtDlp) {
 		yd.l = l
@@ -1,13 +1,16 @@
 package ytdlp
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path"
-	"strings"
+	"strconv"
+
+	"github.com/ailinykh/reposter/v3/pkg/shell"
 )
 
 func New(opts ...func(*YtDlp)) *YtDlp {
@@ -26,37 +29,65 @@ func New(opts ...func(*YtDlp)) *YtDlp {
 	return y
 }
 
+// TempDirPattern matches the directories videos are downloaded to
+const TempDirPattern = "yt-dlp*"
+
+// MaxEntries is the maximum number of items of a multi-item post to fetch
+const MaxEntries = 10
+
 type YtDlp struct {
-	args []string
-	l    *slog.Logger
+	args    []string
+	tempDir string
+	l       *slog.Logger
 }
 
-func (yd *YtDlp) GetFormat(url string) (r *Response, err error) {
-	cmd := strings.Join(append(yd.args, "--dump-json", url), " ")
-	yd.l.Debug("executing", "command", cmd)
+func (yd *YtDlp) GetFormat(ctx context.Context, url string) (r *Response, err error) {
+	args := append(append([]string{}, yd.args[1:]...), "--dump-json", "--no-playlist", "--playlist-items", fmt.Sprintf("1:%d", MaxEntries), "--", url)
+	yd.l.Debug("executing", "command", yd.args[0], "args", args)
 
-	out, err := exec.Command("/bin/sh", "-c", cmd).Output()
+	out, err := shell.Command(ctx, yd.args[0], args...).Output()
 	if err != nil {
 		yd.l.Error("failed to dump json", "url", url, "output", out, "error", err)
 		return nil, fmt.Errorf("failed to dump json: %w", NewError(err, out))
 	}
 
-	err = json.Unmarshal(out, &r)
-	if err != nil {
-		yd.l.Error("unexpected content", "text", out)
-		return nil, fmt.Errorf("failed to parse json: %w", err)
+	// multi-item posts are dumped as one json object per item
+	var entries []*Response
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var e *Response
+		if err = dec.Decode(&e); err != nil {
+			yd.l.Error("unexpected content", "text", out)
+			return nil, fmt.Errorf("failed to parse json: %w", err)
+		}
+		entries = append(entries, e)
 	}
 
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries found for %s", url)
+	}
+
+	r = entries[0]
+	if len(entries) > 1 {
+		r.Entries = entries
+	}
 	return r, nil
 }
 
-func (yd *YtDlp) DownloadFormat(formatID string, resp *Response) (*LocalVideo, error) {
-	dirPath, err := os.MkdirTemp("", "yt-dlp*")
+func (yd *YtDlp) DownloadFormat(ctx context.Context, formatID string, resp *Response) (*LocalVideo, error) {
+	dirPath, err := os.MkdirTemp(yd.tempDir, TempDirPattern)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
 	}
 
-	cmd := strings.Join(append(yd.args,
+	// a video within a playlist comes alone, multi-item posts still have their entries
+	args := append([]string{}, yd.args[1:]...)
+	args = append(args, "--no-playlist")
+	if resp.PlaylistIndex > 0 {
+		args = append(args, "--playlist-items", strconv.Itoa(resp.PlaylistIndex))
+	}
+
+	args = append(args,
 		"--embed-metadata",
 		"--embed-thumbnail",
 		"--convert-thumbnails", "jpg",
@@ -65,13 +96,15 @@ func (yd *YtDlp) DownloadFormat(formatID string, resp *Response) (*LocalVideo, e
 		// "--restrict-filenames", // removes all cyrillic letters
 		"-f", formatID,
 		"-P", dirPath,
-		"-o", `"file.%(ext)s"`,
+		"-o", "file.%(ext)s",
+		"--",
 		resp.WebpageUrl,
-	), " ")
-	yd.l.Debug("executing", "command", strings.Replace(cmd, os.TempDir(), "$TMPDIR/", 1))
+	)
+	yd.l.Debug("executing", "command", yd.args[0], "args", args)
 
-	if out, err := exec.Command("/bin/sh", "-c", cmd).CombinedOutput(); err != nil {
+	if out, err := shell.Command(ctx, yd.args[0], args...).CombinedOutput(); err != nil {
 		yd.l.Error("failed to download video", "extractor", resp.Extractor, "format_id", formatID, "url", resp.WebpageUrl, "output", out)
+		_ = os.RemoveAll(dirPath)
 		return nil, fmt.Errorf("failed to dump json: %w", NewError(err, out))
 	}
 
@@ -80,12 +113,15 @@ func (yd *YtDlp) DownloadFormat(formatID string, resp *Response) (*LocalVideo, e
 	fPath := path.Join(dirPath, "file.mp4")
 	f, err := os.Open(fPath)
 	if err != nil {
+		_ = os.RemoveAll(dirPath)
 		return nil, fmt.Errorf("failed to open local video %s: %w", fPath, err)
 	}
 
 	tPath := path.Join(dirPath, "file.jpg")
 	t, err := os.Open(tPath)
 	if err != nil {
+		f.Close()
+		_ = os.RemoveAll(dirPath)
 		return nil, fmt.Errorf("failed to open local video thumb %s: %w", tPath, err)
 	}
 
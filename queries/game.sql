@@ -36,3 +36,49 @@ INSERT INTO game_rounds (
 ) VALUES (
   $1, $2, $3
 ) RETURNING *;
+
+-- name: DeleteChatPlayers :exec
+DELETE FROM game_players WHERE
+  chat_id = $1
+;
+
+-- name: DeleteChatRounds :exec
+DELETE FROM game_rounds WHERE
+  chat_id = $1
+;
+
+-- name: MoveChatPlayers :exec
+UPDATE game_players SET
+  chat_id = sqlc.arg(new_chat_id), updated_at = NOW()
+WHERE
+  chat_id = sqlc.arg(old_chat_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM game_players p
+    WHERE p.chat_id = sqlc.arg(new_chat_id) AND p.user_id = game_players.user_id
+  )
+;
+
+-- name: MoveChatRounds :exec
+UPDATE game_rounds SET
+  chat_id = sqlc.arg(new_chat_id), updated_at = NOW()
+WHERE
+  chat_id = sqlc.arg(old_chat_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM game_rounds r
+    WHERE r.chat_id = sqlc.arg(new_chat_id) AND r.user_id = game_rounds.user_id
+  )
+;
+
+-- name: RemoveChatPlayers :exec
+UPDATE game_players SET
+  removed_at = NOW()
+WHERE
+  chat_id = $1 AND removed_at IS NULL
+;
+
+-- name: RestoreChatPlayers :exec
+UPDATE game_players SET
+  removed_at = NULL
+WHERE
+  chat_id = $1
+;
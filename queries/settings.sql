@@ -12,4 +12,20 @@ ON CONFLICT (chat_id, key)
 DO UPDATE SET
   value = EXCLUDED.value,
   updated_at = EXCLUDED.updated_at
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: DeleteChatSettings :exec
+DELETE FROM chat_settings WHERE
+  chat_id = $1
+;
+
+-- name: MoveChatSettings :exec
+UPDATE chat_settings SET
+  chat_id = sqlc.arg(new_chat_id), updated_at = NOW()
+WHERE
+  chat_id = sqlc.arg(old_chat_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM chat_settings s
+    WHERE s.chat_id = sqlc.arg(new_chat_id) AND s.key = chat_settings.key
+  )
+;
@@ -0,0 +1,37 @@
+-- name: GetUpdateOffset :one
+SELECT * FROM update_offsets WHERE
+  bot_id = $1
+;
+
+-- name: SetUpdateOffset :exec
+INSERT INTO update_offsets
+  (bot_id, next_update_id)
+VALUES
+  ($1, $2)
+ON CONFLICT (bot_id)
+DO UPDATE SET
+  next_update_id = EXCLUDED.next_update_id,
+  updated_at = NOW()
+WHERE
+  update_offsets.next_update_id < EXCLUDED.next_update_id
+;
+
+-- name: CreateProcessedUpdate :exec
+INSERT INTO processed_updates
+  (bot_id, update_id)
+VALUES
+  ($1, $2)
+ON CONFLICT (bot_id, update_id)
+DO NOTHING
+;
+
+-- name: IsUpdateProcessed :one
+SELECT EXISTS (
+  SELECT 1 FROM processed_updates WHERE
+    bot_id = $1 AND update_id = $2
+);
+
+-- name: DeleteProcessedUpdates :exec
+DELETE FROM processed_updates WHERE
+  bot_id = $1 AND created_at < $2
+;
\ No newline at end of file